@@ -114,8 +114,12 @@ var telegramCmd = &cobra.Command{
 				userID := parts[1]
 
 				// Validate username
-				_, err := loader.LoadCharacter(username)
-				if err != nil {
+				c, err := loader.LoadCharacter(username)
+				if err == nil {
+					if _, convErr := c.ToEntity(loader); convErr != nil {
+						fmt.Printf("Warning: character '%s' cannot be used: %v\n", username, convErr)
+					}
+				} else {
 					_, err = loader.LoadMonster(username)
 				}
 
@@ -0,0 +1,78 @@
+/*
+Copyright © 2026 Paulo Suderio
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/suderio/ancient-draconic/internal/engine"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// manifestCmd groups the commands that work on a world's manifest
+var manifestCmd = &cobra.Command{
+	Use:   "manifest",
+	Short: "Inspect and validate world manifests",
+}
+
+var manifestCheckCmd = &cobra.Command{
+	Use:   "check [world_name]",
+	Short: "Validate a world's manifest.lua",
+	Long: `Loads the world's manifest.lua and reports problems with their file and line:
+syntax and load errors, steps without values, invalid formulas, unknown hook and
+parameter types, restrictions naming unknown commands and references to loops
+that are never created.
+
+Exits with status 1 when any error is found; warnings alone do not fail the check.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		worldDir := resolveWorldDir(cmd, args)
+		path := filepath.Join(worldDir, "manifest.lua")
+		issues := engine.LintManifest(path, worldDir, filepath.Join(worldDir, "data"))
+
+		errors := 0
+		for _, issue := range issues {
+			fmt.Println(issue)
+			if issue.Severity == engine.SeverityError {
+				errors++
+			}
+		}
+		if len(issues) == 0 {
+			fmt.Printf("%s: OK\n", path)
+			return
+		}
+		fmt.Printf("%d error(s), %d warning(s)\n", errors, len(issues)-errors)
+		if errors > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+// resolveWorldDir returns the --world_dir flag, or the directory of the world named by the first
+// argument inside the configured worlds_dir. It exits when neither is given.
+func resolveWorldDir(cmd *cobra.Command, args []string) string {
+	worldDir, _ := cmd.Flags().GetString("world_dir")
+	if worldDir != "" {
+		return worldDir
+	}
+	if len(args) == 0 {
+		fmt.Println("Error: must specify either [world_name] argument or --world_dir flag")
+		os.Exit(1)
+	}
+	worldsDir := viper.GetString("worlds_dir")
+	if worldsDir == "" {
+		worldsDir = "./worlds"
+	}
+	return filepath.Join(worldsDir, args[0])
+}
+
+func init() {
+	rootCmd.AddCommand(manifestCmd)
+	manifestCmd.AddCommand(manifestCheckCmd)
+	manifestCheckCmd.Flags().StringP("world_dir", "w", "", "Location of the world directory (can be relative or absolute path)")
+}
@@ -2,10 +2,14 @@ package cmd
 
 import (
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
 	"strconv"
+	"strings"
 
+	"github.com/suderio/ancient-draconic/internal/engine"
 	"github.com/suderio/ancient-draconic/internal/session"
 	"github.com/suderio/ancient-draconic/internal/telegram"
 
@@ -68,16 +72,65 @@ type botAdapter struct {
 	session *session.Session
 }
 
+// Execute runs a command. Its events are posted to the group chat as a spectator sees them,
+// since every player reads it; the issuing actor also gets them privately as they may see them,
+// when that view shows more.
 func (a *botAdapter) Execute(input string) (*telegram.CommandResult, error) {
-	events, err := a.session.Execute(input)
+	events, err := a.session.ExecuteFrom(session.FrontendTelegram, input)
 	if err != nil {
 		return nil, err
 	}
-	result := &telegram.CommandResult{}
-	for _, evt := range events {
+	chat := a.messages(events, engine.ViewerFor(""))
+	own := a.messages(events, engine.ViewerFor(session.ParseInput(input).ActorID))
+	result := &telegram.CommandResult{Messages: chat}
+	if !slices.Equal(own, chat) {
+		result.Private = own
+	}
+	return result, nil
+}
+
+// messages returns the messages of the events the viewer may see.
+func (a *botAdapter) messages(events []engine.Event, viewer engine.Viewer) []string {
+	var msgs []string
+	for _, evt := range a.session.ViewEvents(events, viewer) {
 		if msg := evt.Message(); msg != "" {
-			result.Messages = append(result.Messages, msg)
+			msgs = append(msgs, msg)
 		}
 	}
-	return result, nil
+	return msgs
+}
+
+// Status renders the game state as seen by the actor, through the session's redacted view. It is
+// sent privately, since it shows the actor's own stats and conditions.
+func (a *botAdapter) Status(actorID string) (*telegram.CommandResult, error) {
+	state := a.session.View(engine.ViewerFor(actorID))
+	return &telegram.CommandResult{Private: []string{renderView(state)}}, nil
+}
+
+// renderView formats a projected game state as a plain text summary.
+func renderView(state *engine.GameState) string {
+	var sb strings.Builder
+	for _, name := range slices.Sorted(maps.Keys(state.Loops)) {
+		if loop := state.Loops[name]; loop.Active {
+			fmt.Fprintf(&sb, "%s: round %d, turn %d\n", name, loop.Round, loop.Turn)
+		}
+	}
+	if len(state.Entities) == 0 {
+		sb.WriteString("No entities active.")
+		return sb.String()
+	}
+	for _, id := range slices.Sorted(maps.Keys(state.Entities)) {
+		ent := state.Entities[id]
+		fmt.Fprintf(&sb, "- %s", ent.Name)
+		if maxHP, ok := ent.Resources["hp"]; ok && maxHP > 0 {
+			fmt.Fprintf(&sb, ": %d/%d HP", maxHP-ent.Spent["hp"], maxHP)
+		} else if label := ent.Statuses["hp"]; label != "" {
+			fmt.Fprintf(&sb, ": %s", label)
+		}
+		if len(ent.Conditions) > 0 {
+			fmt.Fprintf(&sb, " [%s]", strings.Join(ent.Conditions, ", "))
+		}
+		sb.WriteString("\n")
+	}
+	return strings.TrimSuffix(sb.String(), "\n")
 }
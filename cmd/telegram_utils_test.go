@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/suderio/ancient-draconic/internal/session"
+)
+
+const chatManifest = `
+visibility = { hidden_conditions = { "invisible" } }
+
+commands = {
+    vanish = {
+        name = "vanish",
+        params = { { name = "to", type = "target" } },
+        targets = { steps = {
+            { name = "hide", value = "condition('invisible')" },
+            { name = "fall", value = "condition('prone')" },
+        } },
+    },
+}
+`
+
+// chatSession opens a session on a world with the chat manifest, a character and a monster.
+func chatSession(t *testing.T) *session.Session {
+	t.Helper()
+	dir := t.TempDir()
+	for path, content := range map[string]string{
+		"manifest.lua":          chatManifest,
+		"characters/elara.yaml": "id: elara\nname: Elara\n",
+		"monsters/goblin.yaml":  "id: goblin\nname: Goblin\nresources:\n  hp: 7\n",
+	} {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, path)), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, path), []byte(content), 0644))
+	}
+	s, err := session.NewSession([]string{dir}, filepath.Join(dir, "log.jsonl"))
+	require.NoError(t, err)
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func TestBotAdapter_HidesHiddenConditionsFromTheChat(t *testing.T) {
+	s := chatSession(t)
+
+	result, err := (&botAdapter{s}).Execute("vanish by: elara to: goblin")
+	require.NoError(t, err)
+	require.Len(t, result.Messages, 1)
+	assert.Contains(t, result.Messages[0], "prone")
+	assert.Empty(t, result.Private, "the actor sees nothing more than the chat")
+	assert.Equal(t, []string{"invisible", "prone"}, s.State().Entities["goblin"].Conditions)
+}
+
+func TestBotAdapter_KeepsTheGMsSecretsOutOfTheChat(t *testing.T) {
+	s := chatSession(t)
+	adapter := &botAdapter{s}
+
+	result, err := adapter.Execute("roll by: GM dice: 1d20 secret: true")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"The GM keeps a secret (#1)."}, result.Messages)
+	require.Len(t, result.Private, 1)
+	assert.Contains(t, result.Private[0], "GM rolled 1d20")
+
+	// The status shows the viewer's own view, so it is never posted to the chat
+	result, err = adapter.Status("GM")
+	require.NoError(t, err)
+	assert.Empty(t, result.Messages)
+	require.Len(t, result.Private, 1)
+	assert.Contains(t, result.Private[0], "Goblin: 7/7 HP")
+}
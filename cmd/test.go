@@ -0,0 +1,64 @@
+/*
+Copyright © 2026 Paulo Suderio
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/suderio/ancient-draconic/internal/session"
+
+	"github.com/spf13/cobra"
+)
+
+var testCmd = &cobra.Command{
+	Use:   "test [world_name]",
+	Short: "Run a world's rule scenarios",
+	Long: `Runs the scenario files in the world's tests/ directory against its manifest.
+
+A scenario is a YAML file listing the entities to start with, the dice results to
+use and the commands to run, each with the events, messages, errors and entity
+state it must produce. See MANIFEST.md for the format.
+
+Exits with status 1 when any scenario fails.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		worldDir := resolveWorldDir(cmd, args)
+		testsDir, _ := cmd.Flags().GetString("tests_dir")
+		if testsDir == "" {
+			testsDir = filepath.Join(worldDir, "tests")
+		}
+
+		dataDirs := []string{worldDir, filepath.Join(worldDir, "data")}
+		results, err := session.RunScenarios(dataDirs, testsDir)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		failed := 0
+		for _, r := range results {
+			if r.Passed() {
+				fmt.Printf("PASS %s\n", r.Name)
+				continue
+			}
+			failed++
+			fmt.Printf("FAIL %s (%s)\n", r.Name, r.File)
+			for _, f := range r.Failures {
+				fmt.Printf("    %s\n", f)
+			}
+		}
+		fmt.Printf("%d passed, %d failed\n", len(results)-failed, failed)
+		if failed > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(testCmd)
+	testCmd.Flags().StringP("world_dir", "w", "", "Location of the world directory (can be relative or absolute path)")
+	testCmd.Flags().StringP("tests_dir", "t", "", "Directory of the scenario files (default is the world's tests/ directory)")
+}
@@ -1,10 +1,14 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
 	"path/filepath"
+	"sort"
 	"strings"
+	"time"
 
+	"github.com/suderio/ancient-draconic/internal/engine"
 	"github.com/suderio/ancient-draconic/internal/session"
 
 	"github.com/charmbracelet/bubbles/list"
@@ -46,6 +50,11 @@ func (s suggestion) Title() string       { return string(s) }
 func (s suggestion) Description() string { return "" }
 func (s suggestion) FilterValue() string { return string(s) }
 
+// reloadMsg reports the result of an automatic manifest reload.
+type reloadMsg struct {
+	err error
+}
+
 type replModel struct {
 	app          *session.Session
 	textInput    textinput.Model
@@ -124,7 +133,7 @@ func (m *replModel) updateSuggestions() {
 	state := m.app.State()
 
 	// Base hardcoded commands
-	baseCmds := []string{"roll dice: ", "help ", "hint", "ask by: ", "adjudicate ", "allow", "deny", "exit", "quit"}
+	baseCmds := []string{"roll dice: ", "help ", "hint", "ask by: ", "adjudicate ", "allow", "deny", "undo ", "spawn ", "clone of: ", "despawn of: ", "reveal ", "reload", "explain ", "exit", "quit"}
 
 	// Dynamically pull loaded Manifest Commands
 	mf := m.app.Manifest()
@@ -238,16 +247,11 @@ func (m *replModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.updateSuggestions()
 
 				m.logContent += fmt.Sprintf("\n\n> %s\n", val)
-				events, err := m.app.Execute(val)
-				if err != nil {
-					m.logContent += fmt.Sprintf("Error: %v", err)
+				if rest, ok := strings.CutPrefix(val, "explain "); ok {
+					m.logContent += m.explain(strings.TrimSpace(rest))
 				} else {
-					for _, evt := range events {
-						msg := evt.Message()
-						if msg != "" {
-							m.logContent += msg + "\n"
-						}
-					}
+					events, err := m.app.ExecuteFrom(session.FrontendTUI, val)
+					m.logContent += formatResult(events, err)
 				}
 
 				m.viewport.SetContent(m.logContent)
@@ -259,6 +263,15 @@ func (m *replModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.updateSuggestions()
 		}
 
+	case reloadMsg:
+		if msg.err != nil {
+			m.logContent += "\n\n" + m.app.Catalog().Text("session.reload_failed", msg.err)
+		} else {
+			m.logContent += "\n\n" + m.app.Catalog().Text("session.reloaded")
+		}
+		m.viewport.SetContent(m.logContent)
+		m.viewport.GotoBottom()
+
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
@@ -292,6 +305,40 @@ func (m *replModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, tea.Batch(tiCmd, vpCmd, lsCmd)
 }
 
+// formatResult renders the messages of a command's events, or the error it failed with.
+func formatResult(events []engine.Event, err error) string {
+	if err != nil {
+		return fmt.Sprintf("Error: %v", err)
+	}
+	var out strings.Builder
+	for _, evt := range events {
+		if msg := evt.Message(); msg != "" {
+			out.WriteString(msg + "\n")
+		}
+	}
+	return out.String()
+}
+
+// explain dry-runs a command and renders its result followed by the trace of its evaluation,
+// as a tree or, with --json, as indented JSON.
+func (m *replModel) explain(input string) string {
+	input, asJSON := strings.CutPrefix(input, "--json ")
+	events, trace, err := m.app.Explain(session.FrontendTUI, strings.TrimSpace(input))
+	out := formatResult(events, err)
+	if !strings.HasSuffix(out, "\n") {
+		out += "\n"
+	}
+	out += m.app.Catalog().Text("session.explain.dry_run") + "\n"
+	if !asJSON {
+		return out + trace.String()
+	}
+	data, jerr := json.MarshalIndent(trace, "", "  ")
+	if jerr != nil {
+		return out + fmt.Sprintf("Error: %v", jerr)
+	}
+	return out + string(data)
+}
+
 func (m *replModel) renderState() string {
 	var stateView strings.Builder
 	stateView.WriteString("=== Game State ===")
@@ -324,6 +371,9 @@ func (m *replModel) renderState() string {
 			if len(ent.Conditions) > 0 {
 				conds = fmt.Sprintf(" [%s]", strings.Join(ent.Conditions, ", "))
 			}
+			if len(ent.Hooks) > 0 {
+				conds += fmt.Sprintf(" {%s}", strings.Join(hooksToStrings(ent.Hooks), ", "))
+			}
 			hp := ent.Resources["hp"] - ent.Spent["hp"]
 			maxHP := ent.Resources["hp"]
 			if maxHP > 0 {
@@ -346,6 +396,23 @@ func orderToStrings(order map[string]int) []string {
 	return result
 }
 
+// hooksToStrings renders an entity's active hooks, showing remaining firings for counted hooks.
+func hooksToStrings(hooks map[string]engine.Hook) []string {
+	var result []string
+	for name, h := range hooks {
+		switch h.Repeats {
+		case engine.HookRepeatCount:
+			result = append(result, fmt.Sprintf("%s x%d", name, h.Remaining))
+		case engine.HookRepeatAlways:
+			result = append(result, name+" ∞")
+		default:
+			result = append(result, name)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
 func (m *replModel) View() string {
 	if m.width == 0 {
 		return "Initializing..."
@@ -377,6 +444,11 @@ func (m *replModel) View() string {
 func RunTUI(app *session.Session, worldDir, campaignDir string) error {
 	m := newREPLModel(app, filepath.Base(worldDir), filepath.Base(campaignDir))
 	p := tea.NewProgram(&m, tea.WithAltScreen())
+
+	// Reload the manifest whenever it (or a module it requires) is saved
+	stop := app.Watch(time.Second, func(err error) { p.Send(reloadMsg{err: err}) })
+	defer stop()
+
 	if _, err := p.Run(); err != nil {
 		return err
 	}
@@ -0,0 +1,269 @@
+package data
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/suderio/ancient-draconic/internal/engine"
+)
+
+// skillAbilities maps each SRD skill to the ability score it is based on.
+var skillAbilities = map[string]string{
+	"acrobatics":      "dex",
+	"animal_handling": "wis",
+	"arcana":          "int",
+	"athletics":       "str",
+	"deception":       "cha",
+	"history":         "int",
+	"insight":         "wis",
+	"intimidation":    "cha",
+	"investigation":   "int",
+	"medicine":        "wis",
+	"nature":          "int",
+	"perception":      "wis",
+	"performance":     "cha",
+	"persuasion":      "cha",
+	"religion":        "int",
+	"sleight_of_hand": "dex",
+	"stealth":         "dex",
+	"survival":        "wis",
+}
+
+// ToEntity converts an SRD monster into an engine entity.
+//
+// Abilities, proficiency bonus and AC become stats; hp and speeds become resources (walking
+// speed as "speed"), together with one action, bonus action and reaction per turn. SRD
+// proficiencies hold total bonuses, so they are stored as multipliers of the proficiency bonus
+// (1 for proficient, 2 for expertise), keyed like the manifest expects: "stealth",
+// "sleight_of_hand", "save_dex". Size, type, alignment, hit dice and defenses become classes.
+func (m *Monster) ToEntity() *engine.Entity {
+	e := engine.NewEntity(m.Index, m.Name)
+	e.Types = append(e.Types, "monster", strings.ToLower(m.Type))
+	if m.Subtype != "" {
+		e.Types = append(e.Types, strings.ToLower(m.Subtype))
+	}
+
+	e.Stats = m.GetStats()
+	e.Resources["hp"] = m.HitPoints
+	e.Resources["actions"] = 1
+	e.Resources["bonus_actions"] = 1
+	e.Resources["reactions"] = 1
+	for mode, value := range m.Speed {
+		feet, ok := parseFeet(value)
+		if !ok {
+			continue
+		}
+		if mode == "walk" {
+			mode = "speed"
+		}
+		e.Resources[mode] = feet
+	}
+
+	e.Proficiencies = proficiencyMultipliers(m.Proficiencies, e.Stats)
+
+	setClass(e, "size", strings.ToLower(m.Size))
+	setClass(e, "type", strings.ToLower(m.Type))
+	setClass(e, "alignment", m.Alignment)
+	hitDice := m.HitPointsRoll
+	if hitDice == "" {
+		hitDice = m.HitDice
+	}
+	setClass(e, "hit_dice", hitDice)
+	setDefenses(e, m.DamageResistances, m.DamageImmunities, m.DamageVulnerabilities)
+	var conditions []string
+	for _, c := range m.ConditionImmunities {
+		conditions = append(conditions, c.Index)
+	}
+	setClass(e, "condition_immunities", strings.Join(conditions, ", "))
+
+	for _, a := range m.Actions {
+		e.Actions[actionID(a.Name)] = a.toEngine()
+	}
+	return e
+}
+
+// abilityScores lists the six ability score keys in sheet order.
+var abilityScores = []string{"str", "dex", "con", "int", "wis", "cha"}
+
+// ToEntity converts a character sheet into an engine entity, deriving what a sheet leaves implicit.
+//
+// The sheet's ability scores are final, racial bonuses included, so only the race's speed, size
+// and traits are applied; traits that grant proficiencies (e.g. Keen Senses) add them.
+// Proficiencies are stored as multipliers of the proficiency bonus, as for monsters. Stats also
+// receive each ability modifier ("dex_mod"), every saving throw ("save_dex") and skill ("stealth")
+// total bonus, and "passive_perception". HP falls back to the maximum of the hit die plus the
+// constitution modifier.
+func (c *Character) ToEntity(l *Loader) (*engine.Entity, error) {
+	e := engine.NewEntity(c.Index, c.Name)
+	e.Types = append(e.Types, "character")
+	if c.Type != "" {
+		e.Types = append(e.Types, strings.ToLower(c.Type))
+	}
+
+	e.Stats = c.GetStats()
+	e.Proficiencies = proficiencyMultipliers(c.Proficiencies, e.Stats)
+
+	speed, size := 30, c.Size
+	if c.Race != "" {
+		race, err := l.LoadRace(c.Race)
+		if err != nil {
+			return nil, fmt.Errorf("character %s: %w", c.Index, err)
+		}
+		if race.Speed > 0 {
+			speed = race.Speed
+		}
+		if size == "" {
+			size = race.Size
+		}
+
+		var traits []string
+		for _, ref := range race.Traits {
+			trait, err := l.LoadTrait(ref.Index)
+			if err != nil {
+				return nil, fmt.Errorf("character %s: %w", c.Index, err)
+			}
+			traits = append(traits, trait.Index)
+			for _, p := range trait.Proficiencies {
+				if key, _, ok := proficiencyKey(p.Index); ok {
+					e.Proficiencies[key] = max(e.Proficiencies[key], 1)
+				}
+			}
+		}
+		setClass(e, "race", race.Index)
+		setClass(e, "traits", strings.Join(traits, ", "))
+	}
+
+	deriveBonuses(e)
+	if _, ok := e.Stats["ac"]; !ok {
+		e.Stats["ac"] = 10 + e.Stats["dex_mod"]
+	}
+
+	hp := c.HitPoints
+	if hp == 0 {
+		hp = hitDieMax(c.HitDice) + e.Stats["con_mod"]
+	}
+	e.Resources["hp"] = max(hp, 1)
+	e.Resources["actions"] = 1
+	e.Resources["bonus_actions"] = 1
+	e.Resources["reactions"] = 1
+	e.Resources["speed"] = speed
+
+	setClass(e, "size", strings.ToLower(size))
+	setClass(e, "type", strings.ToLower(c.Type))
+	setClass(e, "alignment", c.Alignment)
+	hitDice := c.HitPointsRoll
+	if hitDice == "" {
+		hitDice = c.HitDice
+	}
+	setClass(e, "hit_dice", hitDice)
+	var res, imm, vul []string
+	for _, d := range c.Defenses {
+		res = append(res, d.Resistances...)
+		imm = append(imm, d.Immunities...)
+		vul = append(vul, d.Vulnerabilities...)
+	}
+	setDefenses(e, res, imm, vul)
+
+	for _, a := range c.Actions {
+		e.Actions[actionID(a.Name)] = a.toEngine()
+	}
+	return e, nil
+}
+
+// deriveBonuses adds ability modifiers and saving throw and skill totals to the entity stats.
+func deriveBonuses(e *engine.Entity) {
+	bonus := e.Stats["prof_bonus"]
+	for _, a := range abilityScores {
+		mod := CalculateModifier(e.Stats[a])
+		e.Stats[a+"_mod"] = mod
+		e.Stats["save_"+a] = mod + e.Proficiencies["save_"+a]*bonus
+	}
+	for skill, a := range skillAbilities {
+		e.Stats[skill] = e.Stats[a+"_mod"] + e.Proficiencies[skill]*bonus
+	}
+	e.Stats["passive_perception"] = 10 + e.Stats["perception"]
+}
+
+// hitDieMax returns the highest roll of a hit die such as "1d8".
+func hitDieMax(dice string) int {
+	var count, sides int
+	if _, err := fmt.Sscanf(dice, "%dd%d", &count, &sides); err != nil {
+		return 0
+	}
+	return sides
+}
+
+// toEngine converts an SRD action into an engine action.
+func (a Action) toEngine() engine.Action {
+	action := engine.Action{Name: a.Name, Desc: a.Desc, AttackBonus: a.AttackBonus}
+	for _, d := range a.Damage {
+		action.Damage = append(action.Damage, engine.ActionDamage{Dice: d.DamageDice, Type: d.DamageType.Index})
+	}
+	if a.DC != nil {
+		action.DC = a.DC.Value
+		action.DCAbility = a.DC.Type.Index
+		action.DCSuccess = a.DC.SuccessType
+	}
+	return action
+}
+
+// proficiencyMultipliers converts SRD proficiency totals into multipliers of the proficiency bonus.
+func proficiencyMultipliers(profs []Proficiency, stats map[string]int) map[string]int {
+	result := make(map[string]int)
+	bonus := stats["prof_bonus"]
+	if bonus <= 0 {
+		return result
+	}
+	for _, p := range profs {
+		key, ability, ok := proficiencyKey(p.Proficiency.Index)
+		if !ok {
+			continue
+		}
+		multiplier := (p.Value - CalculateModifier(stats[ability]) + bonus/2) / bonus
+		if multiplier > 0 {
+			result[key] = multiplier
+		}
+	}
+	return result
+}
+
+// proficiencyKey maps an SRD proficiency index ("skill-sleight-of-hand", "saving-throw-dex")
+// to the entity proficiency key and the ability it is based on.
+func proficiencyKey(index string) (key, ability string, ok bool) {
+	if skill, found := strings.CutPrefix(index, "skill-"); found {
+		key = strings.ReplaceAll(skill, "-", "_")
+		ability, ok = skillAbilities[key]
+		return key, ability, ok
+	}
+	if save, found := strings.CutPrefix(index, "saving-throw-"); found {
+		return "save_" + save, save, true
+	}
+	return "", "", false
+}
+
+// parseFeet extracts the distance from an SRD speed such as "30 ft.".
+func parseFeet(value string) (int, bool) {
+	n, err := strconv.Atoi(strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(value), "ft.")))
+	return n, err == nil
+}
+
+// actionID turns an action name into a lookup key ("Fire Breath (Recharge 5-6)" → "fire_breath").
+func actionID(name string) string {
+	if i := strings.Index(name, "("); i > 0 {
+		name = name[:i]
+	}
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(name)), " ", "_")
+}
+
+func setDefenses(e *engine.Entity, resistances, immunities, vulnerabilities []string) {
+	setClass(e, "resistances", strings.Join(resistances, ", "))
+	setClass(e, "immunities", strings.Join(immunities, ", "))
+	setClass(e, "vulnerabilities", strings.Join(vulnerabilities, ", "))
+}
+
+func setClass(e *engine.Entity, key, value string) {
+	if value != "" {
+		e.Classes[key] = value
+	}
+}
@@ -0,0 +1,129 @@
+package data
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMonsterToEntity_Goblin(t *testing.T) {
+	m, err := NewLoader(nil).LoadMonster("goblin")
+	require.NoError(t, err)
+
+	e := m.ToEntity()
+	assert.Equal(t, "goblin", e.ID)
+	assert.Equal(t, "Goblin", e.Name)
+	assert.Equal(t, []string{"monster", "humanoid", "goblinoid"}, e.Types)
+	assert.Equal(t, 14, e.Stats["dex"])
+	assert.Equal(t, 15, e.Stats["ac"])
+	assert.Equal(t, 2, e.Stats["prof_bonus"])
+	assert.Equal(t, 7, e.Resources["hp"])
+	assert.Equal(t, 30, e.Resources["speed"])
+	assert.Equal(t, 1, e.Resources["actions"])
+	// Stealth +6 with dex +2 and proficiency +2 is expertise
+	assert.Equal(t, 2, e.Proficiencies["stealth"])
+	assert.Equal(t, "small", e.Classes["size"])
+	assert.Equal(t, "2d6", e.Classes["hit_dice"])
+
+	require.Contains(t, e.Actions, "scimitar")
+	assert.Equal(t, 4, e.Actions["scimitar"].AttackBonus)
+	assert.Equal(t, "1d6+2", e.Actions["scimitar"].Damage[0].Dice)
+	assert.Equal(t, "slashing", e.Actions["scimitar"].Damage[0].Type)
+}
+
+func TestMonsterToEntity_DefensesSavesAndDC(t *testing.T) {
+	m, err := NewLoader(nil).LoadMonster("adult red dragon")
+	require.NoError(t, err)
+
+	e := m.ToEntity()
+	assert.Equal(t, 80, e.Resources["fly"])
+	assert.Equal(t, 40, e.Resources["climb"])
+	assert.Equal(t, 1, e.Proficiencies["save_dex"])
+	assert.Equal(t, "fire", e.Classes["immunities"])
+
+	breath := e.Actions["fire_breath"]
+	assert.Equal(t, 21, breath.DC)
+	assert.Equal(t, "dex", breath.DCAbility)
+
+	skeleton, err := NewLoader(nil).LoadMonster("skeleton")
+	require.NoError(t, err)
+	e = skeleton.ToEntity()
+	assert.Equal(t, "bludgeoning", e.Classes["vulnerabilities"])
+	assert.Equal(t, "poisoned, exhaustion", e.Classes["condition_immunities"])
+}
+
+func TestMonsterToEntity_AllSRDMonsters(t *testing.T) {
+	paths, err := srdFS.ReadDir("srd/monsters")
+	require.NoError(t, err)
+	require.NotEmpty(t, paths)
+
+	loader := NewLoader(nil)
+	for _, p := range paths {
+		index := p.Name()[:len(p.Name())-len(filepath.Ext(p.Name()))]
+		m, err := loader.LoadMonster(index)
+		require.NoError(t, err, index)
+		e := m.ToEntity()
+		assert.Positive(t, e.Resources["hp"], index)
+		assert.Positive(t, e.Stats["ac"], index)
+	}
+}
+
+func TestCharacterToEntity_DerivedValues(t *testing.T) {
+	loader := NewLoader(nil)
+	c, err := loader.LoadCharacter("elara")
+	require.NoError(t, err)
+
+	e, err := c.ToEntity(loader)
+	require.NoError(t, err)
+	assert.Equal(t, "elara-shadowstep", e.ID)
+	assert.Equal(t, []string{"character", "humanoid"}, e.Types)
+	assert.Equal(t, 3, e.Stats["dex_mod"])
+	assert.Equal(t, -1, e.Stats["str_mod"])
+	// Stealth +7 is expertise; acrobatics +5 and dex saves +5 are plain proficiency
+	assert.Equal(t, 2, e.Proficiencies["stealth"])
+	assert.Equal(t, 1, e.Proficiencies["acrobatics"])
+	assert.Equal(t, 7, e.Stats["stealth"])
+	assert.Equal(t, 5, e.Stats["save_dex"])
+	assert.Equal(t, -1, e.Stats["save_str"])
+	assert.Equal(t, 10, e.Stats["passive_perception"])
+	assert.Equal(t, 14, e.Stats["ac"])
+	assert.Equal(t, 9, e.Resources["hp"])
+	assert.Equal(t, 30, e.Resources["speed"])
+	assert.Equal(t, "fire, radiant", e.Classes["resistances"])
+	assert.Contains(t, e.Actions, "shortsword")
+}
+
+func TestCharacterToEntity_Race(t *testing.T) {
+	loader := NewLoader(nil)
+	c, err := loader.LoadCharacter("elara")
+	require.NoError(t, err)
+	c.Race = "elf"
+	c.HitPoints = 0
+
+	e, err := c.ToEntity(loader)
+	require.NoError(t, err)
+	// The sheet's scores already include the racial bonuses
+	assert.Equal(t, 16, e.Stats["dex"])
+	assert.Equal(t, 3, e.Stats["dex_mod"])
+	assert.Equal(t, 7, e.Stats["stealth"])
+	// Keen Senses grants perception
+	assert.Equal(t, 1, e.Proficiencies["perception"])
+	assert.Equal(t, 2, e.Stats["perception"])
+	assert.Equal(t, "elf", e.Classes["race"])
+	assert.Contains(t, e.Classes["traits"], "keen-senses")
+	// Max of 1d8 plus con +1
+	assert.Equal(t, 9, e.Resources["hp"])
+
+	c.Race = "minotaur"
+	_, err = c.ToEntity(loader)
+	assert.Error(t, err)
+}
+
+func TestCalculateModifier(t *testing.T) {
+	assert.Equal(t, -5, CalculateModifier(1))
+	assert.Equal(t, -1, CalculateModifier(9))
+	assert.Equal(t, 0, CalculateModifier(11))
+	assert.Equal(t, 10, CalculateModifier(30))
+}
@@ -2,9 +2,11 @@ package data
 
 import (
 	"embed"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 
 	"gopkg.in/yaml.v3"
@@ -13,6 +15,12 @@ import (
 //go:embed srd/**/*.yaml
 var srdFS embed.FS
 
+// ErrNotFound is wrapped by load errors when no data directory nor the embedded SRD has the record.
+var ErrNotFound = errors.New("record not found")
+
+// recordNamePattern accepts SRD categories and indexes such as "magic-items" or "bag-of-holding".
+var recordNamePattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
 // Loader handles reading and instantiating records from the read-only data layer
 type Loader struct {
 	dataDirs []string
@@ -66,6 +74,67 @@ func (l *Loader) LoadRace(name string) (*Race, error) {
 	return &r, nil
 }
 
+// LoadTrait constructs a typed Trait object by searching through the SRD traits
+func (l *Loader) LoadTrait(name string) (*Trait, error) {
+	var t Trait
+	dashName := strings.ReplaceAll(strings.ToLower(name), " ", "-")
+	ref := filepath.Join("traits", fmt.Sprintf("%s.yaml", dashName))
+	if err := l.load(ref, &t); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// LoadRecord reads any record, e.g. LoadRecord("spells", "fireball"), as generic YAML values.
+// Names are lowercased and spaces and underscores become dashes ("Magic Items" → "magic-items").
+func (l *Loader) LoadRecord(category, index string) (map[string]any, error) {
+	category, index = recordName(category), recordName(index)
+	if !recordNamePattern.MatchString(category) || !recordNamePattern.MatchString(index) {
+		return nil, fmt.Errorf("invalid record reference %s/%s", category, index)
+	}
+	var record map[string]any
+	if err := l.load(filepath.Join(category, index+".yaml"), &record); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// recordName normalizes a record category or index to the SRD file naming.
+func recordName(name string) string {
+	return strings.NewReplacer(" ", "-", "_", "-").Replace(strings.ToLower(strings.TrimSpace(name)))
+}
+
+// LoadCharacterFile constructs a typed Character object from a specific YAML file
+func LoadCharacterFile(path string) (*Character, error) {
+	var c Character
+	if err := loadFile(path, &c); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// LoadMonsterFile constructs a typed Monster object from a specific YAML file
+func LoadMonsterFile(path string) (*Monster, error) {
+	var m Monster
+	if err := loadFile(path, &m); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+func loadFile(path string, target any) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer f.Close()
+
+	if err := yaml.NewDecoder(f).Decode(target); err != nil {
+		return fmt.Errorf("failed to decode %s: %w", path, err)
+	}
+	return nil
+}
+
 func (l *Loader) load(ref string, target any) error {
 	// 1. Check external directories (Campaign/World/etc)
 	for _, dir := range l.dataDirs {
@@ -103,5 +172,5 @@ func (l *Loader) load(ref string, target any) error {
 		return nil
 	}
 
-	return fmt.Errorf("could not find or open reference %s in any available data directory or embedded storage", ref)
+	return fmt.Errorf("could not find or open reference %s in any available data directory or embedded storage: %w", ref, ErrNotFound)
 }
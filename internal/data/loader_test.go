@@ -0,0 +1,43 @@
+package data
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadRecord(t *testing.T) {
+	l := NewLoader(nil)
+
+	spell, err := l.LoadRecord("spells", "Fireball")
+	require.NoError(t, err)
+	assert.Equal(t, "Fireball", spell["name"])
+	assert.Equal(t, 3, spell["level"])
+	damage := spell["damage"].(map[string]any)
+	assert.Equal(t, "8d6", damage["damage_at_slot_level"].(map[string]any)["3"])
+
+	item, err := l.LoadRecord("magic_items", "bag of holding")
+	require.NoError(t, err)
+	assert.Equal(t, "bag-of-holding", item["index"])
+
+	_, err = l.LoadRecord("spells", "wish-for-more-wishes")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	_, err = l.LoadRecord("spells", "../../go")
+	assert.ErrorContains(t, err, "invalid record reference")
+	assert.NotErrorIs(t, err, ErrNotFound)
+}
+
+func TestLoadRecord_Override(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "spells"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "spells", "fireball.yaml"), []byte("index: fireball\nname: Homebrew Fireball\nlevel: 2\n"), 0644))
+
+	spell, err := NewLoader([]string{dir}).LoadRecord("spells", "fireball")
+	require.NoError(t, err)
+	assert.Equal(t, "Homebrew Fireball", spell["name"])
+	assert.Equal(t, 2, spell["level"])
+}
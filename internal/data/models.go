@@ -46,14 +46,22 @@ type Proficiency struct {
 	Value       int       `yaml:"value"`
 }
 
+// DifficultyClass represents the saving throw an action forces (e.g. DC 21 Dexterity, half on success)
+type DifficultyClass struct {
+	Type        Reference `yaml:"dc_type"`
+	Value       int       `yaml:"dc_value"`
+	SuccessType string    `yaml:"success_type"`
+}
+
 // Action represents an action a monster can take
 type Action struct {
-	Name        string   `yaml:"name"`
-	Desc        string   `yaml:"desc"`
-	AttackBonus int      `yaml:"attack_bonus"`
-	HitRule     string   `yaml:"hit_rule" json:"hit_rule"` // CEL formula for hit resolution
-	Damage      []Damage `yaml:"damage"`
-	Recharge    string   `yaml:"recharge"`
+	Name        string           `yaml:"name"`
+	Desc        string           `yaml:"desc"`
+	AttackBonus int              `yaml:"attack_bonus"`
+	HitRule     string           `yaml:"hit_rule" json:"hit_rule"` // CEL formula for hit resolution
+	Damage      []Damage         `yaml:"damage"`
+	DC          *DifficultyClass `yaml:"dc"`
+	Recharge    string           `yaml:"recharge"`
 }
 
 // Defense defines a creature's damage modifiers
@@ -71,26 +79,32 @@ type ArmorClass struct {
 
 // Monster represents a basic monster from the SRD loaded via YAML.
 type Monster struct {
-	Index            string            `yaml:"index"`
-	Name             string            `yaml:"name"`
-	Size             string            `yaml:"size"`
-	Type             string            `yaml:"type"`
-	Alignment        string            `yaml:"alignment"`
-	ArmorClass       []ArmorClass      `yaml:"armor_class"`
-	HitPoints        int               `yaml:"hit_points"`
-	HitDice          string            `yaml:"hit_dice"`
-	Speed            map[string]string `yaml:"speed"`
-	Strength         int               `yaml:"strength"`
-	Dexterity        int               `yaml:"dexterity"`
-	Constitution     int               `yaml:"constitution"`
-	Intelligence     int               `yaml:"intelligence"`
-	Wisdom           int               `yaml:"wisdom"`
-	Charisma         int               `yaml:"charisma"`
-	ProficiencyBonus int               `yaml:"proficiency_bonus"`
-	Actions          []Action          `yaml:"actions"`
-	Proficiencies    []Proficiency     `yaml:"proficiencies"`
-	Defenses         []Defense         `yaml:"defenses"`
-	SpecialAbilities []Ability         `yaml:"special_abilities"`
+	Index                 string            `yaml:"index"`
+	Name                  string            `yaml:"name"`
+	Size                  string            `yaml:"size"`
+	Type                  string            `yaml:"type"`
+	Subtype               string            `yaml:"subtype"`
+	Alignment             string            `yaml:"alignment"`
+	ArmorClass            []ArmorClass      `yaml:"armor_class"`
+	HitPoints             int               `yaml:"hit_points"`
+	HitDice               string            `yaml:"hit_dice"`
+	HitPointsRoll         string            `yaml:"hit_points_roll"`
+	Speed                 map[string]string `yaml:"speed"`
+	DamageResistances     []string          `yaml:"damage_resistances"`
+	DamageImmunities      []string          `yaml:"damage_immunities"`
+	DamageVulnerabilities []string          `yaml:"damage_vulnerabilities"`
+	ConditionImmunities   []Reference       `yaml:"condition_immunities"`
+	Strength              int               `yaml:"strength"`
+	Dexterity             int               `yaml:"dexterity"`
+	Constitution          int               `yaml:"constitution"`
+	Intelligence          int               `yaml:"intelligence"`
+	Wisdom                int               `yaml:"wisdom"`
+	Charisma              int               `yaml:"charisma"`
+	ProficiencyBonus      int               `yaml:"proficiency_bonus"`
+	Actions               []Action          `yaml:"actions"`
+	Proficiencies         []Proficiency     `yaml:"proficiencies"`
+	Defenses              []Defense         `yaml:"defenses"`
+	SpecialAbilities      []Ability         `yaml:"special_abilities"`
 }
 
 func (m *Monster) GetStats() map[string]int {
@@ -113,11 +127,28 @@ func (m *Monster) GetAbilities() []Ability {
 	return m.SpecialAbilities
 }
 
+// AbilityBonus is a racial increase to an ability score
+type AbilityBonus struct {
+	AbilityScore Reference `yaml:"ability_score"`
+	Bonus        int       `yaml:"bonus"`
+}
+
 // Race represents a character race from the SRD.
 type Race struct {
-	Index string `yaml:"index"`
-	Name  string `yaml:"name"`
-	Size  string `yaml:"size"`
+	Index          string         `yaml:"index"`
+	Name           string         `yaml:"name"`
+	Size           string         `yaml:"size"`
+	Speed          int            `yaml:"speed"`
+	AbilityBonuses []AbilityBonus `yaml:"ability_bonuses"`
+	Traits         []Reference    `yaml:"traits"`
+}
+
+// Trait represents a racial trait from the SRD (e.g. Darkvision, Keen Senses).
+type Trait struct {
+	Index         string      `yaml:"index"`
+	Name          string      `yaml:"name"`
+	Desc          []string    `yaml:"desc"`
+	Proficiencies []Reference `yaml:"proficiencies"`
 }
 
 // Character represents a player character from the data loaded via YAML.
@@ -125,8 +156,12 @@ type Character struct {
 	Index            string        `yaml:"index"`
 	Name             string        `yaml:"name"`
 	Race             string        `yaml:"race"`
+	Type             string        `yaml:"type"`
+	Size             string        `yaml:"size"`
+	Alignment        string        `yaml:"alignment"`
 	HitPoints        int           `yaml:"hit_points"`
 	HitDice          string        `yaml:"hit_dice"`
+	HitPointsRoll    string        `yaml:"hit_points_roll"`
 	ArmorClass       []ArmorClass  `yaml:"armor_class"`
 	Strength         int           `yaml:"strength"`
 	Dexterity        int           `yaml:"dexterity"`
@@ -163,5 +198,9 @@ func (c *Character) GetAbilities() []Ability {
 
 // CalculateModifier returns the standard D&D 5e ability modifier for a given score.
 func CalculateModifier(score int) int {
-	return (score - 10) / 2
+	mod := score - 10
+	if mod < 0 {
+		mod--
+	}
+	return mod / 2
 }
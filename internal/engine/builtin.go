@@ -2,6 +2,7 @@ package engine
 
 import (
 	"fmt"
+	"maps"
 	"strings"
 )
 
@@ -15,6 +16,11 @@ var builtinCommands = map[string]bool{
 	"allow":      true,
 	"deny":       true,
 	"undo":       true,
+	"spawn":      true,
+	"clone":      true,
+	"despawn":    true,
+	"reveal":     true,
+	"reload":     true,
 }
 
 // isBuiltin returns true if the command is a built-in that is not defined in the manifest.
@@ -22,6 +28,22 @@ func isBuiltin(cmdName string) bool {
 	return builtinCommands[cmdName]
 }
 
+// resolveSpawnAlias rewrites "spawn <template>" (parsed as the command "spawn_<template>")
+// into the spawn builtin with a template parameter, unless the manifest defines that command.
+func resolveSpawnAlias(cmdName string, params map[string]any, m *Manifest) (string, map[string]any) {
+	template, ok := strings.CutPrefix(cmdName, "spawn_")
+	if !ok || template == "" {
+		return cmdName, params
+	}
+	if _, defined := m.Commands[cmdName]; defined {
+		return cmdName, params
+	}
+	resolved := make(map[string]any, len(params)+1)
+	maps.Copy(resolved, params)
+	resolved["template"] = template
+	return "spawn", resolved
+}
+
 // executeBuiltin dispatches a built-in command.
 func executeBuiltin(
 	cmdName string,
@@ -32,38 +54,52 @@ func executeBuiltin(
 	m *Manifest,
 	eval *LuaEvaluator,
 ) ([]Event, error) {
+	c := m.Catalog
 	switch cmdName {
 	case "roll":
-		return executeRoll(actorID, params, eval)
+		return executeRoll(c, actorID, params, state, eval)
 	case "help":
 		return executeHelp(params, m)
 	case "hint":
 		return executeHint(state, m)
 	case "ask":
-		return executeAsk(actorID, targets, params)
+		return executeAsk(c, actorID, targets, params)
 	case "allow":
-		return executeAllow(actorID, state)
+		return executeAllow(c, actorID, state)
 	case "deny":
-		return executeDeny(actorID, state)
+		return executeDeny(c, actorID, state)
 	case "adjudicate":
-		return executeAdjudicate(actorID, state)
+		return executeAdjudicate(c, actorID, state)
 	case "undo":
-		return executeUndo(actorID, params)
+		return executeUndo(c, actorID, params)
+	case "spawn":
+		return executeSpawn(c, actorID, params)
+	case "clone":
+		return executeClone(c, actorID, targets, params, state, eval)
+	case "despawn":
+		return executeDespawn(c, actorID, targets, state)
+	case "reveal":
+		return executeReveal(c, actorID, params, state)
+	case "reload":
+		return executeReload(c, actorID)
 	}
 	return nil, fmt.Errorf("unknown builtin command: %s", cmdName)
 }
 
-// executeRoll evaluates a dice expression and returns a DiceRolledEvent.
-// Expected params: {"dice": "2d6+3"}
-func executeRoll(actorID string, params map[string]any, eval *LuaEvaluator) ([]Event, error) {
+// executeRoll evaluates a dice expression and returns a DiceRolledEvent,
+// hidden inside a SecretEvent when the roll is secret.
+// Expected params: {"dice": "2d6+3", "secret": "true"}
+func executeRoll(c *Catalog, actorID string, params map[string]any, state *GameState, eval *LuaEvaluator) ([]Event, error) {
 	dice, ok := params["dice"].(string)
 	if !ok || dice == "" {
-		return nil, fmt.Errorf("roll requires a 'dice' parameter (e.g., roll dice: 2d6+3)")
+		return nil, c.Error("builtin.roll.dice")
 	}
-	result := eval.rollFunc(dice)
-	return []Event{
-		&DiceRolledEvent{ActorID: actorID, Dice: dice, Result: result},
-	}, nil
+	result := eval.roll(dice)
+	events := []Event{&DiceRolledEvent{ActorID: actorID, Dice: dice, Result: result}}
+	if isTruthy(params["secret"]) {
+		return wrapSecret(state, nil, events), nil
+	}
+	return events, nil
 }
 
 // executeHelp returns help text from the manifest.
@@ -73,46 +109,57 @@ func executeHelp(params map[string]any, m *Manifest) ([]Event, error) {
 	if cmdName, ok := params["command"].(string); ok && cmdName != "" {
 		// Help for a specific command
 		if cmd, ok := m.Commands[cmdName]; ok {
-			helpText := fmt.Sprintf("**%s**: %s\nUsage: %s", cmd.Name, cmd.Help, cmd.Error)
-			return []Event{&HintEvent{MessageStr: helpText}}, nil
+			return []Event{&HintEvent{MessageStr: commandHelp(cmd, m.Catalog)}}, nil
 		}
 		// Try underscore variant (e.g., "encounter start" → "encounter_start")
 		underscore := strings.ReplaceAll(cmdName, " ", "_")
 		if cmd, ok := m.Commands[underscore]; ok {
-			helpText := fmt.Sprintf("**%s**: %s\nUsage: %s", cmd.Name, cmd.Help, cmd.Error)
-			return []Event{&HintEvent{MessageStr: helpText}}, nil
+			return []Event{&HintEvent{MessageStr: commandHelp(cmd, m.Catalog)}}, nil
 		}
-		return nil, fmt.Errorf("unknown command: %s", cmdName)
+		return nil, m.Catalog.Error("command.unknown", cmdName)
 	}
 
 	// List all commands
 	var lines []string
-	lines = append(lines, "**Available commands:**")
+	lines = append(lines, m.Catalog.Text("builtin.help.list"))
 	// Hardcoded commands
-	lines = append(lines, "  roll, help, hint, ask, adjudicate, allow, deny")
+	lines = append(lines, "  roll, help, hint, ask, adjudicate, allow, deny, undo, spawn, clone, despawn, reveal, reload")
 	// Manifest commands
 	for _, cmd := range m.Commands {
-		lines = append(lines, fmt.Sprintf("  **%s** — %s", cmd.Name, cmd.Help))
+		line := fmt.Sprintf("  **%s** — %s", cmd.Name, cmd.Help)
+		if cmd.Layer != "" {
+			line += fmt.Sprintf(" [%s]", cmd.Layer)
+		}
+		lines = append(lines, line)
 	}
 	return []Event{&HintEvent{MessageStr: strings.Join(lines, "\n")}}, nil
 }
 
+// commandHelp renders the help of one command, naming the overlay that defines it, if any.
+func commandHelp(cmd CommandDef, c *Catalog) string {
+	text := c.Text("builtin.help.command", cmd.Name, cmd.Help, cmd.Error)
+	if cmd.Layer != "" {
+		text += "\n" + c.Text("builtin.help.layer", cmd.Layer)
+	}
+	return text
+}
+
 // executeHint returns the hint text from the last executed command.
 func executeHint(state *GameState, m *Manifest) ([]Event, error) {
 	if state.LastCommand == "" {
-		return []Event{&HintEvent{MessageStr: "No command has been executed yet."}}, nil
+		return []Event{&HintEvent{MessageStr: m.Catalog.Text("builtin.hint.none")}}, nil
 	}
 	if cmd, ok := m.Commands[state.LastCommand]; ok && cmd.Hint != "" {
 		return []Event{&HintEvent{MessageStr: cmd.Hint}}, nil
 	}
-	return []Event{&HintEvent{MessageStr: fmt.Sprintf("No hint available for '%s'.", state.LastCommand)}}, nil
+	return []Event{&HintEvent{MessageStr: m.Catalog.Text("builtin.hint.missing", state.LastCommand)}}, nil
 }
 
 // executeAsk emits an AskIssuedEvent to request input from targets.
 // Expected params: {"options": ["command1", "command2"]}
-func executeAsk(actorID string, targets []string, params map[string]any) ([]Event, error) {
+func executeAsk(c *Catalog, actorID string, targets []string, params map[string]any) ([]Event, error) {
 	if len(targets) == 0 {
-		return nil, fmt.Errorf("ask requires at least one target")
+		return nil, c.Error("builtin.ask.target")
 	}
 
 	options, _ := params["options"].([]string)
@@ -135,9 +182,9 @@ func executeAsk(actorID string, targets []string, params map[string]any) ([]Even
 }
 
 // executeAllow resolves a pending ask by approving it.
-func executeAllow(actorID string, state *GameState) ([]Event, error) {
+func executeAllow(c *Catalog, actorID string, state *GameState) ([]Event, error) {
 	if !isGM(actorID) {
-		return nil, fmt.Errorf("only the GM can use 'allow'")
+		return nil, c.Error("builtin.gm_only", "allow")
 	}
 	// Clear pending ask and record approval
 	delete(state.Metadata, "pending_ask")
@@ -147,9 +194,9 @@ func executeAllow(actorID string, state *GameState) ([]Event, error) {
 }
 
 // executeDeny resolves a pending ask by rejecting it.
-func executeDeny(actorID string, state *GameState) ([]Event, error) {
+func executeDeny(c *Catalog, actorID string, state *GameState) ([]Event, error) {
 	if !isGM(actorID) {
-		return nil, fmt.Errorf("only the GM can use 'deny'")
+		return nil, c.Error("builtin.gm_only", "deny")
 	}
 	delete(state.Metadata, "pending_ask")
 	return []Event{
@@ -159,14 +206,14 @@ func executeDeny(actorID string, state *GameState) ([]Event, error) {
 
 // executeAdjudicate is similar to allow but may involve more context.
 // For now, it behaves like allow.
-func executeAdjudicate(actorID string, state *GameState) ([]Event, error) {
-	return executeAllow(actorID, state)
+func executeAdjudicate(c *Catalog, actorID string, state *GameState) ([]Event, error) {
+	return executeAllow(c, actorID, state)
 }
 
 // executeUndo parses an undo command and yields an UndoRequestEvent for the session layer.
-func executeUndo(actorID string, params map[string]any) ([]Event, error) {
+func executeUndo(c *Catalog, actorID string, params map[string]any) ([]Event, error) {
 	if !isGM(actorID) {
-		return nil, fmt.Errorf("unauthorized: undo can only be executed by the GM")
+		return nil, c.Error("command.gm_only", "undo")
 	}
 
 	evt := &UndoRequestEvent{}
@@ -184,3 +231,116 @@ func executeUndo(actorID string, params map[string]any) ([]Event, error) {
 
 	return []Event{evt}, nil
 }
+
+// executeReload yields a ReloadRequestEvent for the session layer, which reloads the manifest.
+func executeReload(c *Catalog, actorID string) ([]Event, error) {
+	if !isGM(actorID) {
+		return nil, c.Error("command.gm_only", "reload")
+	}
+	return []Event{&ReloadRequestEvent{}}, nil
+}
+
+// executeSpawn yields a SpawnRequestEvent for the session layer, which resolves the template.
+// Expected params: {"template": "goblin", "count": "4", "name": "Goblin"}
+func executeSpawn(c *Catalog, actorID string, params map[string]any) ([]Event, error) {
+	if !isGM(actorID) {
+		return nil, c.Error("command.gm_only", "spawn")
+	}
+	template, _ := params["template"].(string)
+	if template == "" {
+		return nil, c.Error("builtin.spawn.template")
+	}
+
+	evt := &SpawnRequestEvent{ActorID: actorID, Template: template, Count: 1}
+	if count, ok := params["count"]; ok {
+		n, ok := toInt(count)
+		if !ok || n < 1 || n > MaxSpawnCount {
+			return nil, c.Error("builtin.spawn.count", MaxSpawnCount)
+		}
+		evt.Count = n
+	}
+	evt.Name, _ = params["name"].(string)
+
+	return []Event{evt}, nil
+}
+
+// executeClone copies an existing entity, e.g. "clone of: Goblin_A count: 2".
+func executeClone(c *Catalog, actorID string, targets []string, params map[string]any, state *GameState, eval *LuaEvaluator) ([]Event, error) {
+	if !isGM(actorID) {
+		return nil, c.Error("command.gm_only", "clone")
+	}
+	if len(targets) != 1 {
+		return nil, c.Error("builtin.clone.entity")
+	}
+	source, ok := state.Entities[targets[0]]
+	if !ok {
+		return nil, c.Error("builtin.entity_not_found", targets[0])
+	}
+
+	count := 1
+	if raw, ok := params["count"]; ok {
+		n, ok := toInt(raw)
+		if !ok || n < 1 || n > MaxSpawnCount {
+			return nil, c.Error("builtin.clone.count", MaxSpawnCount)
+		}
+		count = n
+	}
+	name, _ := params["name"].(string)
+
+	return SpawnEntities(state, source, name, count, eval)
+}
+
+// executeDespawn removes entities from the game, e.g. "despawn of: Goblin_A and Goblin_B".
+// An entity named twice is removed once.
+func executeDespawn(c *Catalog, actorID string, targets []string, state *GameState) ([]Event, error) {
+	if !isGM(actorID) {
+		return nil, c.Error("command.gm_only", "despawn")
+	}
+	if len(targets) == 0 {
+		return nil, c.Error("builtin.despawn.entity")
+	}
+
+	var events []Event
+	seen := make(map[string]bool)
+	for _, id := range targets {
+		if _, ok := state.Entities[id]; !ok {
+			return nil, c.Error("builtin.entity_not_found", id)
+		}
+		if !seen[id] {
+			seen[id] = true
+			events = append(events, &EntityRemovedEvent{ActorID: id})
+		}
+	}
+	return events, nil
+}
+
+// executeReveal publishes a secret result to the players, e.g. "reveal id: 2".
+// Without an id, the most recent unrevealed secret is revealed.
+func executeReveal(c *Catalog, actorID string, params map[string]any, state *GameState) ([]Event, error) {
+	if !isGM(actorID) {
+		return nil, c.Error("command.gm_only", "reveal")
+	}
+
+	if raw, ok := params["id"]; ok {
+		id, ok := toInt(raw)
+		if !ok {
+			return nil, c.Error("builtin.reveal.id")
+		}
+		for _, secret := range state.Secrets {
+			if secret.ID == id {
+				if secret.Revealed {
+					return nil, c.Error("builtin.reveal.revealed", id)
+				}
+				return []Event{&SecretRevealedEvent{ID: id, Messages: secret.Messages}}, nil
+			}
+		}
+		return nil, c.Error("builtin.reveal.not_found", id)
+	}
+
+	for i := len(state.Secrets) - 1; i >= 0; i-- {
+		if secret := state.Secrets[i]; !secret.Revealed {
+			return []Event{&SecretRevealedEvent{ID: secret.ID, Messages: secret.Messages}}, nil
+		}
+	}
+	return nil, c.Error("builtin.reveal.none")
+}
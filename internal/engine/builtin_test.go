@@ -24,7 +24,7 @@ func TestExecuteRoll_Success(t *testing.T) {
 	require.NoError(t, err)
 	defer eval.Close()
 
-	events, err := executeRoll("fighter", map[string]any{"dice": "1d20"}, eval)
+	events, err := executeRoll(nil, "fighter", map[string]any{"dice": "1d20"}, NewGameState(), eval)
 	require.NoError(t, err)
 	require.Len(t, events, 1)
 	dre, ok := events[0].(*DiceRolledEvent)
@@ -39,11 +39,11 @@ func TestExecuteRoll_MissingDice(t *testing.T) {
 	require.NoError(t, err)
 	defer eval.Close()
 
-	_, err = executeRoll("fighter", map[string]any{}, eval)
+	_, err = executeRoll(nil, "fighter", map[string]any{}, NewGameState(), eval)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "dice")
 
-	_, err = executeRoll("fighter", map[string]any{"dice": ""}, eval)
+	_, err = executeRoll(nil, "fighter", map[string]any{"dice": ""}, NewGameState(), eval)
 	assert.Error(t, err)
 }
 
@@ -132,7 +132,7 @@ func TestExecuteHint_NoHintForCommand(t *testing.T) {
 }
 
 func TestExecuteAsk_Success(t *testing.T) {
-	events, err := executeAsk("GM", []string{"player1", "player2"}, map[string]any{
+	events, err := executeAsk(nil, "GM", []string{"player1", "player2"}, map[string]any{
 		"options": []any{"attack", "defend"},
 	})
 	require.NoError(t, err)
@@ -140,13 +140,13 @@ func TestExecuteAsk_Success(t *testing.T) {
 }
 
 func TestExecuteAsk_NoTargets(t *testing.T) {
-	_, err := executeAsk("GM", nil, map[string]any{})
+	_, err := executeAsk(nil, "GM", nil, map[string]any{})
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "requires at least one target")
 }
 
 func TestExecuteAsk_NoOptions(t *testing.T) {
-	events, err := executeAsk("GM", []string{"player1"}, map[string]any{})
+	events, err := executeAsk(nil, "GM", []string{"player1"}, map[string]any{})
 	require.NoError(t, err)
 	assert.Len(t, events, 1)
 }
@@ -155,7 +155,7 @@ func TestExecuteAllow_Success(t *testing.T) {
 	state := NewGameState()
 	state.Metadata["pending_ask"] = map[string]any{"target": "player1"}
 
-	events, err := executeAllow("GM", state)
+	events, err := executeAllow(nil, "GM", state)
 	require.NoError(t, err)
 	require.Len(t, events, 1)
 	assert.Nil(t, state.Metadata["pending_ask"])
@@ -163,7 +163,7 @@ func TestExecuteAllow_Success(t *testing.T) {
 
 func TestExecuteAllow_NotGM(t *testing.T) {
 	state := NewGameState()
-	_, err := executeAllow("player1", state)
+	_, err := executeAllow(nil, "player1", state)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "only the GM")
 }
@@ -172,7 +172,7 @@ func TestExecuteDeny_Success(t *testing.T) {
 	state := NewGameState()
 	state.Metadata["pending_ask"] = map[string]any{"target": "player1"}
 
-	events, err := executeDeny("GM", state)
+	events, err := executeDeny(nil, "GM", state)
 	require.NoError(t, err)
 	require.Len(t, events, 1)
 	assert.Nil(t, state.Metadata["pending_ask"])
@@ -180,13 +180,13 @@ func TestExecuteDeny_Success(t *testing.T) {
 
 func TestExecuteDeny_NotGM(t *testing.T) {
 	state := NewGameState()
-	_, err := executeDeny("player1", state)
+	_, err := executeDeny(nil, "player1", state)
 	assert.Error(t, err)
 }
 
 func TestExecuteAdjudicate(t *testing.T) {
 	state := NewGameState()
-	events, err := executeAdjudicate("GM", state)
+	events, err := executeAdjudicate(nil, "GM", state)
 	require.NoError(t, err)
 	assert.Len(t, events, 1)
 }
@@ -237,3 +237,136 @@ func TestExecuteBuiltin_AllCommands(t *testing.T) {
 		})
 	}
 }
+
+func TestExecuteSpawn_Alias(t *testing.T) {
+	state := NewGameState()
+	m := &Manifest{Commands: map[string]CommandDef{}}
+	eval, err := NewLuaEvaluator(func(dice string) int { return 10 })
+	require.NoError(t, err)
+	defer eval.Close()
+
+	events, err := ExecuteCommand("spawn_goblin", "GM", nil, map[string]any{"count": "4"}, state, m, eval)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	req, ok := events[0].(*SpawnRequestEvent)
+	require.True(t, ok)
+	assert.Equal(t, "goblin", req.Template)
+	assert.Equal(t, 4, req.Count)
+
+	_, err = ExecuteCommand("spawn_goblin", "fighter", nil, map[string]any{}, state, m, eval)
+	assert.ErrorContains(t, err, "unauthorized")
+
+	_, err = ExecuteCommand("spawn", "GM", nil, map[string]any{"template": "goblin", "count": "zero"}, state, m, eval)
+	assert.ErrorContains(t, err, "count")
+	_, err = ExecuteCommand("spawn", "GM", nil, map[string]any{"template": "goblin", "count": "100000"}, state, m, eval)
+	assert.EqualError(t, err, "spawn count must be between 1 and 50")
+}
+
+func TestSpawnEntities_LetteredIDsAndRolledHP(t *testing.T) {
+	state := NewGameState()
+	state.Entities["Goblin_B"] = NewEntity("Goblin_B", "Goblin B")
+
+	var rolled []string
+	eval, err := NewLuaEvaluator(func(dice string) int {
+		rolled = append(rolled, dice)
+		return 5
+	})
+	require.NoError(t, err)
+	defer eval.Close()
+
+	template := NewEntity("goblin", "Goblin")
+	template.Classes["hit_dice"] = "2d6"
+	template.Resources["hp"] = 7
+	template.Spent["hp"] = 3
+
+	events, err := SpawnEntities(state, template, "", 3, eval)
+	require.NoError(t, err)
+	require.Len(t, events, 3)
+
+	var ids []string
+	for _, evt := range events {
+		ent := evt.(*EntityCreatedEvent).Entity
+		ids = append(ids, ent.ID)
+		assert.Equal(t, 5, ent.Resources["hp"])
+		assert.Empty(t, ent.Spent)
+		assert.Equal(t, "Goblin", ent.Classes["template"])
+	}
+	assert.Equal(t, []string{"Goblin_A", "Goblin_C", "Goblin_D"}, ids)
+	assert.Equal(t, []string{"2d6", "2d6", "2d6"}, rolled)
+
+	single, err := SpawnEntities(state, template, "Boss", 1, eval)
+	require.NoError(t, err)
+	assert.Equal(t, "Boss", single[0].(*EntityCreatedEvent).Entity.ID)
+}
+
+func TestExecuteCloneAndDespawn(t *testing.T) {
+	state := NewGameState()
+	goblin := NewEntity("Goblin_A", "Goblin A")
+	goblin.Classes["template"] = "Goblin"
+	state.Entities["Goblin_A"] = goblin
+	m := &Manifest{Commands: map[string]CommandDef{}}
+	eval, err := NewLuaEvaluator(func(dice string) int { return 10 })
+	require.NoError(t, err)
+	defer eval.Close()
+
+	events, err := executeBuiltin("clone", "GM", []string{"Goblin_A"}, map[string]any{"count": "2"}, state, m, eval)
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.Equal(t, "Goblin_B", events[0].(*EntityCreatedEvent).Entity.ID)
+	assert.Equal(t, "Goblin_C", events[1].(*EntityCreatedEvent).Entity.ID)
+
+	_, err = executeBuiltin("clone", "GM", []string{"Orc"}, nil, state, m, eval)
+	assert.ErrorContains(t, err, "not found")
+	_, err = executeBuiltin("clone", "GM", []string{"Goblin_A"}, map[string]any{"count": "51"}, state, m, eval)
+	assert.EqualError(t, err, "clone count must be between 1 and 50")
+
+	events, err = executeBuiltin("despawn", "GM", []string{"Goblin_A"}, nil, state, m, eval)
+	require.NoError(t, err)
+	assert.Equal(t, &EntityRemovedEvent{ActorID: "Goblin_A"}, events[0])
+	// An entity named twice is removed once
+	state.Entities["Goblin_B"] = NewEntity("Goblin_B", "Goblin B")
+	events, err = executeBuiltin("despawn", "GM", []string{"Goblin_A", "Goblin_B", "Goblin_A"}, nil, state, m, eval)
+	require.NoError(t, err)
+	assert.Equal(t, []Event{&EntityRemovedEvent{ActorID: "Goblin_A"}, &EntityRemovedEvent{ActorID: "Goblin_B"}}, events)
+
+	_, err = executeBuiltin("despawn", "fighter", []string{"Goblin_A"}, nil, state, m, eval)
+	assert.ErrorContains(t, err, "unauthorized")
+}
+
+func TestExecuteReveal(t *testing.T) {
+	state := NewGameState()
+	m := &Manifest{Commands: map[string]CommandDef{}}
+	eval, err := NewLuaEvaluator(func(dice string) int { return 10 })
+	require.NoError(t, err)
+	defer eval.Close()
+
+	_, err = executeBuiltin("reveal", "GM", nil, map[string]any{}, state, m, eval)
+	assert.ErrorContains(t, err, "no secrets")
+
+	for _, dice := range []string{"1d20", "1d4"} {
+		events, err := executeBuiltin("roll", "GM", nil, map[string]any{"dice": dice, "secret": "yes"}, state, m, eval)
+		require.NoError(t, err)
+		require.NoError(t, events[0].Apply(state))
+	}
+	require.Len(t, state.Secrets, 2)
+
+	// Without an id, the latest unrevealed secret is revealed
+	events, err := executeBuiltin("reveal", "GM", nil, map[string]any{}, state, m, eval)
+	require.NoError(t, err)
+	revealed := events[0].(*SecretRevealedEvent)
+	assert.Equal(t, 2, revealed.ID)
+	assert.Equal(t, "GM reveals secret #2: GM rolled 1d4 = 10", revealed.Message())
+	require.NoError(t, revealed.Apply(state))
+	assert.True(t, state.Secrets[1].Revealed)
+
+	_, err = executeBuiltin("reveal", "GM", nil, map[string]any{"id": "2"}, state, m, eval)
+	assert.ErrorContains(t, err, "already revealed")
+	_, err = executeBuiltin("reveal", "GM", nil, map[string]any{"id": "7"}, state, m, eval)
+	assert.ErrorContains(t, err, "not found")
+	_, err = executeBuiltin("reveal", "fighter", nil, map[string]any{"id": "1"}, state, m, eval)
+	assert.ErrorContains(t, err, "unauthorized")
+
+	events, err = executeBuiltin("reveal", "GM", nil, map[string]any{"id": "1"}, state, m, eval)
+	require.NoError(t, err)
+	assert.Equal(t, 1, events[0].(*SecretRevealedEvent).ID)
+}
@@ -0,0 +1,55 @@
+package engine
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Envelope describes a logged event: its sequence number, when it was logged and the command
+// it came from. It is written alongside the event's data in every log entry.
+type Envelope struct {
+	Seq      int64     `json:"seq,omitempty"`      // from 1, monotonically increasing; not reused after undo
+	Time     time.Time `json:"time,omitzero"`      // when the event was logged
+	Actor    string    `json:"actor,omitempty"`    // the actor that issued the command
+	Frontend string    `json:"frontend,omitempty"` // e.g., "tui", "telegram", "cli"
+	Command  string    `json:"command,omitempty"`  // the command's raw text
+}
+
+// RecordedEvent is a logged event together with the envelope of its log entry. It behaves as
+// the event it wraps.
+type RecordedEvent struct {
+	Event
+	Envelope
+}
+
+// Unwrap returns the recorded event.
+func (e *RecordedEvent) Unwrap() Event { return e.Event }
+
+func (e *RecordedEvent) MarshalJSON() ([]byte, error) { return json.Marshal(e.Event) }
+
+// Unwrap returns the event behind the wrappers that add an envelope or a narration to it, or
+// the event itself.
+func Unwrap(evt Event) Event {
+	for {
+		w, ok := evt.(interface{ Unwrap() Event })
+		if !ok {
+			return evt
+		}
+		evt = w.Unwrap()
+	}
+}
+
+// As finds the first event of type T among evt and the events it wraps, like errors.As.
+func As[T Event](evt Event) (T, bool) {
+	for {
+		if t, ok := evt.(T); ok {
+			return t, true
+		}
+		w, ok := evt.(interface{ Unwrap() Event })
+		if !ok {
+			var zero T
+			return zero, false
+		}
+		evt = w.Unwrap()
+	}
+}
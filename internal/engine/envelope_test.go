@@ -0,0 +1,30 @@
+package engine
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordedEvent_BehavesAsTheEventItWraps(t *testing.T) {
+	evt := &ConditionEvent{ActorID: "goblin", Condition: "prone", Add: true}
+	rec := &RecordedEvent{Event: &NarratedEvent{Event: evt, Text: "The goblin falls"}, Envelope: Envelope{Seq: 7, Actor: "fighter"}}
+
+	assert.Equal(t, "ConditionEvent", rec.Type())
+	assert.Equal(t, "The goblin falls", rec.Message())
+	assert.Same(t, evt, Unwrap(rec))
+	data, err := json.Marshal(rec)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"actor_id":"goblin","condition":"prone","add":true}`, string(data))
+
+	narrated, ok := As[*NarratedEvent](rec)
+	require.True(t, ok)
+	assert.Equal(t, "The goblin falls", narrated.Text)
+	found, ok := As[*RecordedEvent](rec)
+	require.True(t, ok)
+	assert.Equal(t, int64(7), found.Seq)
+	_, ok = As[*SecretEvent](rec)
+	assert.False(t, ok)
+}
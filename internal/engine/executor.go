@@ -17,31 +17,35 @@ func ExecuteCommand(
 	m *Manifest,
 	eval *LuaEvaluator,
 ) ([]Event, error) {
+	cmdName, params = resolveSpawnAlias(cmdName, params, m)
 	if isBuiltin(cmdName) {
 		return executeBuiltin(cmdName, actorID, targets, params, state, m, eval)
 	}
 
 	cmdDef, ok := m.Commands[cmdName]
 	if !ok {
-		return nil, fmt.Errorf("unknown command: %s", cmdName)
+		return nil, m.Catalog.Error("command.unknown", cmdName)
 	}
 
 	if err := checkRestrictions(cmdName, actorID, m); err != nil {
 		return nil, err
 	}
 
-	if err := validateParams(cmdDef, params); err != nil {
-		return nil, fmt.Errorf("invalid parameters for %s: %w. Usage: %s", cmdDef.Name, err, cmdDef.Error)
+	if err := validateParams(cmdDef, params, m.Catalog); err != nil {
+		return nil, m.Catalog.Error("command.invalid_params", cmdDef.Name, err, cmdDef.Error)
 	}
 
 	actor := state.Entities[actorID]
 
 	// Evaluate prereqs
+	tr := eval.trace
 	ctx := BuildContext(state, actor, nil, params, nil, nil, nil)
 	for _, prereq := range cmdDef.Prereq {
+		tr.open(TracePrereq, prereq.Name, traceInput(actorID, "", params))
 		result, err := eval.Eval(prereq.Value, ctx)
+		tr.close(result, err)
 		if err != nil {
-			return nil, fmt.Errorf("prereq '%s' evaluation failed: %w", prereq.Name, err)
+			return nil, fmt.Errorf("%s: prereq '%s' evaluation failed: %w", cmdName, prereq.Name, err)
 		}
 		passed, ok := result.(bool)
 		if !ok || !passed {
@@ -52,29 +56,33 @@ func ExecuteCommand(
 	// Execute game steps (run once)
 	var events []Event
 	gameResults := make(map[string]any)
+	tr.open(TracePhase, PhaseGame, nil)
 	for _, step := range cmdDef.Game.Steps {
+		tr.open(TraceStep, step.Name, traceInput(actorID, "", params, gameResults))
 		ctx = BuildContext(state, actor, nil, params, gameResults, nil, nil)
 		result, err := eval.Eval(step.Value, ctx)
 		if err != nil {
-			return nil, fmt.Errorf("game step '%s' failed: %w", step.Name, err)
+			tr.close(nil, err)
+			return nil, fmt.Errorf("%s: game step '%s' failed: %w", cmdName, step.Name, err)
 		}
 		evts, plain := dispatchTaggedResult(result, actorID, "", cmdName, state)
+		if step.Secret {
+			evts = wrapSecret(state, events, evts)
+		}
+		tr.close(plain, nil, evts...)
 		gameResults[step.Name] = plain
 		events = append(events, evts...)
 	}
 	// Add Game Hooks
 	for _, hook := range cmdDef.Game.Hooks {
-		events = append(events, &HookAddedEvent{
+		evt := &HookAddedEvent{
 			TargetID: "", // Global hooks
-			Hook: Hook{
-				Name:          hook.Name,
-				Type:          hook.Type,
-				TargetID:      "",
-				SourceCommand: cmdName,
-				Value:         hook.Value,
-			},
-		})
+			Hook:     newHook(hook, PhaseGame, cmdName, actorID, "", params),
+		}
+		tr.add(TraceEvent, evt.Type(), MessageIn(evt, eval.catalog))
+		events = append(events, evt)
 	}
+	tr.close(nil, nil)
 
 	// Execute target steps (run per-target)
 	allTargets := resolveTargets(cmdDef, targets, params)
@@ -82,67 +90,124 @@ func ExecuteCommand(
 		target := state.Entities[targetID]
 		targetResults := make(map[string]any)
 
+		tr.open(TracePhase, PhaseTargets+" "+targetID, nil)
 		for _, step := range cmdDef.Targets.Steps {
+			tr.open(TraceStep, step.Name, traceInput(actorID, targetID, params, gameResults, targetResults))
 			ctx = BuildContext(state, actor, target, params, gameResults, targetResults, nil)
 			result, err := eval.Eval(step.Value, ctx)
 			if err != nil {
-				return nil, fmt.Errorf("target step '%s' for %s failed: %w", step.Name, targetID, err)
+				tr.close(nil, err)
+				return nil, fmt.Errorf("%s: target step '%s' for %s failed: %w", cmdName, step.Name, targetID, err)
 			}
 			evts, plain := dispatchTaggedResult(result, actorID, targetID, cmdName, state)
+			if step.Secret {
+				evts = wrapSecret(state, events, evts)
+			}
+			tr.close(plain, nil, evts...)
 			targetResults[step.Name] = plain
 			events = append(events, evts...)
 		}
 
 		// Add Target Hooks
 		for _, hook := range cmdDef.Targets.Hooks {
-			events = append(events, &HookAddedEvent{
+			evt := &HookAddedEvent{
 				TargetID: targetID,
-				Hook: Hook{
-					Name:          hook.Name,
-					Type:          hook.Type,
-					TargetID:      targetID,
-					SourceCommand: cmdName,
-					Value:         hook.Value,
-				},
-			})
+				Hook:     newHook(hook, PhaseTargets, cmdName, actorID, targetID, params),
+			}
+			tr.add(TraceEvent, evt.Type(), MessageIn(evt, eval.catalog))
+			events = append(events, evt)
 		}
+		tr.close(nil, nil)
 	}
 
 	// Execute actor steps (run once, affecting the actor)
 	actorResults := make(map[string]any)
+	tr.open(TracePhase, PhaseActor, nil)
 	for _, step := range cmdDef.Actor.Steps {
+		tr.open(TraceStep, step.Name, traceInput(actorID, "", params, gameResults, nil, actorResults))
 		ctx = BuildContext(state, actor, nil, params, gameResults, nil, actorResults)
 		result, err := eval.Eval(step.Value, ctx)
 		if err != nil {
-			return nil, fmt.Errorf("actor step '%s' failed: %w", step.Name, err)
+			tr.close(nil, err)
+			return nil, fmt.Errorf("%s: actor step '%s' failed: %w", cmdName, step.Name, err)
 		}
 		evts, plain := dispatchTaggedResult(result, actorID, "", cmdName, state)
+		if step.Secret {
+			evts = wrapSecret(state, events, evts)
+		}
+		tr.close(plain, nil, evts...)
 		actorResults[step.Name] = plain
 		events = append(events, evts...)
 	}
 
 	// Add Actor Hooks
 	for _, hook := range cmdDef.Actor.Hooks {
-		events = append(events, &HookAddedEvent{
+		evt := &HookAddedEvent{
 			TargetID: actorID,
-			Hook: Hook{
-				Name:          hook.Name,
-				Type:          hook.Type,
-				TargetID:      actorID,
-				SourceCommand: cmdName,
-				Value:         hook.Value,
-			},
-		})
+			Hook:     newHook(hook, PhaseActor, cmdName, actorID, actorID, params),
+		}
+		tr.add(TraceEvent, evt.Type(), MessageIn(evt, eval.catalog))
+		events = append(events, evt)
 	}
+	tr.close(nil, nil)
 
 	state.LastCommand = cmdName
 	return events, nil
 }
 
+// newHook instantiates a hook definition for the given watched entity (empty for global hooks),
+// capturing the issuing actor and command parameters so the hook can be re-bound after a restart.
+func newHook(def HookDef, phase, cmdName, actorID, targetID string, params map[string]any) Hook {
+	repeats := def.Repeats
+	if repeats == "" {
+		repeats = HookRepeatOnce
+	}
+	return Hook{
+		Name:          def.Name,
+		Type:          def.Type,
+		TargetID:      targetID,
+		SourceCommand: cmdName,
+		Phase:         phase,
+		ActorID:       actorID,
+		Params:        params,
+		Repeats:       repeats,
+		Remaining:     def.Count,
+		Value:         def.Value,
+		StopWhen:      def.StopWhen,
+	}
+}
+
+// wrapSecret hides the events of a secret step inside a SecretEvent, numbered after the
+// secrets already in the state and those pending in the current command.
+func wrapSecret(state *GameState, pending []Event, evts []Event) []Event {
+	if len(evts) == 0 {
+		return nil
+	}
+	id := len(state.Secrets) + 1
+	for _, evt := range pending {
+		if IsSecret(evt) {
+			id++
+		}
+	}
+	return []Event{&SecretEvent{ID: id, Events: evts}}
+}
+
 // dispatchTaggedResult inspects the Eval result. If it is a map with an `_event` key,
 // it dispatches the appropriate Event(s) and returns them along with a clean value for step results.
-// If there is no `_event` key, it returns (nil, result) — a pure computation step.
+// A list holding tagged results dispatches each of them in order; its clean value is the list of
+// their clean values. If there is no `_event` key, it returns (nil, result) — a pure computation step.
 func dispatchTaggedResult(result any, actorID, targetID, cmdName string, state *GameState) ([]Event, any) {
+	if list, ok := result.([]any); ok && isTagged(list) {
+		var events []Event
+		plain := make([]any, len(list))
+		for i, item := range list {
+			var evts []Event
+			evts, plain[i] = dispatchTaggedResult(item, actorID, targetID, cmdName, state)
+			events = append(events, evts...)
+		}
+		return events, plain
+	}
+
 	m, ok := result.(map[string]any)
 	if !ok {
 		return nil, result
@@ -186,24 +251,22 @@ func dispatchTaggedResult(result any, actorID, targetID, cmdName string, state *
 		return []Event{&LoopOrderEvent{LoopName: name, ActorID: actorID, Value: value}}, value
 
 	case "add_actor":
+		name, _ := m["name"].(string)
+		if name == "" {
+			name = cmdName
+		}
 		actors := m["actors"]
 		switch v := actors.(type) {
 		case string:
-			name, _ := m["name"].(string)
-			if name == "" {
-				name = cmdName
-			}
 			return []Event{&ActorAddedEvent{LoopName: name, ActorID: v}}, v
 		case []any:
-			if len(v) > 0 {
-				if s, ok := v[0].(string); ok {
-					name, _ := m["name"].(string)
-					if name == "" {
-						name = cmdName
-					}
-					return []Event{&ActorAddedEvent{LoopName: name, ActorID: s}}, actors
+			var events []Event
+			for _, a := range v {
+				if id, ok := a.(string); ok {
+					events = append(events, &ActorAddedEvent{LoopName: name, ActorID: id})
 				}
 			}
+			return events, actors
 		}
 		return nil, result
 
@@ -272,21 +335,41 @@ func dispatchTaggedResult(result any, actorID, targetID, cmdName string, state *
 		return dispatchNextTurn(m, actorID, cmdName, state)
 
 	default:
-		// Unknown event type — treat as CustomEvent
 		// If emit() was used, the result has { _event, payload } — unwrap the payload.
-		if p, ok := m["payload"].(map[string]any); ok {
-			return []Event{&CustomEvent{EventType: eventType, ActorID: actorID, Payload: p}}, m
-		}
-		payload := make(map[string]any)
-		for k, v := range m {
-			if k != "_event" {
-				payload[k] = v
+		payload, ok := m["payload"].(map[string]any)
+		if !ok {
+			payload = make(map[string]any)
+			for k, v := range m {
+				if k != "_event" {
+					payload[k] = v
+				}
 			}
 		}
+		// Event types declared in the manifest have their own reducer
+		if b, ok := state.events[eventType]; ok {
+			return []Event{b.newEvent(actorID, targetID, payload)}, m
+		}
+		// Unknown event type — treat as CustomEvent
 		return []Event{&CustomEvent{EventType: eventType, ActorID: actorID, Payload: payload}}, m
 	}
 }
 
+// isTagged reports whether a result, or any item of a result list, is a tagged table.
+func isTagged(result any) bool {
+	switch v := result.(type) {
+	case map[string]any:
+		_, ok := v["_event"].(string)
+		return ok
+	case []any:
+		for _, item := range v {
+			if isTagged(item) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // dispatchNextTurn handles the next_turn tagged result by emitting multiple events:
 // TurnEndedEvent → (RoundStartedEvent if wrap-around) → TurnStartedEvent.
 func dispatchNextTurn(m map[string]any, actorID, cmdName string, state *GameState) ([]Event, any) {
@@ -354,7 +437,7 @@ func sortedActors(loop *Loop) []string {
 func checkRestrictions(cmdName, actorID string, m *Manifest) error {
 	for _, gmCmd := range m.Restrictions.GMCommands {
 		if cmdName == gmCmd && !isGM(actorID) {
-			return fmt.Errorf("unauthorized: %s can only be executed by the GM", cmdName)
+			return m.Catalog.Error("command.gm_only", cmdName)
 		}
 	}
 	return nil
@@ -364,11 +447,11 @@ func isGM(actorID string) bool {
 	return strings.ToUpper(actorID) == "GM"
 }
 
-func validateParams(cmd CommandDef, params map[string]any) error {
+func validateParams(cmd CommandDef, params map[string]any, c *Catalog) error {
 	for _, p := range cmd.Params {
 		if p.Required {
 			if _, ok := params[p.Name]; !ok {
-				return fmt.Errorf("missing required parameter: %s", p.Name)
+				return c.Error("command.missing_param", p.Name)
 			}
 		}
 	}
@@ -2,6 +2,7 @@ package engine
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -314,6 +315,43 @@ func TestRollCommand(t *testing.T) {
 	assert.Equal(t, 10, roll.Result)
 }
 
+func TestSecretRollAndStep(t *testing.T) {
+	m := &Manifest{
+		Commands: map[string]CommandDef{
+			"insight": {
+				Name: "insight",
+				Game: CommandPhase{Steps: []GameStep{
+					{Name: "read", Value: "check_result(roll('1d20') >= 10)", Secret: true},
+					{Name: "note", Value: "emit('noted', {})"},
+				}},
+			},
+		},
+	}
+	state := testState()
+	eval, err := NewLuaEvaluator(mockRoll)
+	require.NoError(t, err)
+
+	events, err := ExecuteCommand("roll", "GM", nil, map[string]any{"dice": "1d20", "secret": "true"}, state, m, eval)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	secret, ok := events[0].(*SecretEvent)
+	require.True(t, ok)
+	assert.True(t, IsSecret(secret))
+	assert.Equal(t, 1, secret.ID)
+	assert.Equal(t, "[secret #1] GM rolled 1d20 = 10", secret.Message())
+	require.NoError(t, secret.Apply(state))
+
+	events, err = ExecuteCommand("insight", "fighter", nil, nil, state, m, eval)
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	secret, ok = events[0].(*SecretEvent)
+	require.True(t, ok)
+	assert.Equal(t, 2, secret.ID)
+	require.Len(t, secret.Events, 1)
+	assert.IsType(t, &CheckEvent{}, secret.Events[0])
+	assert.False(t, IsSecret(events[1]))
+}
+
 func TestCustomEvent(t *testing.T) {
 	m := &Manifest{
 		Commands: map[string]CommandDef{
@@ -342,6 +380,57 @@ func TestCustomEvent(t *testing.T) {
 	assert.Equal(t, 42, state.Metadata["arcane_blast"].(map[string]any)["power"])
 }
 
+func TestStepReturningAList(t *testing.T) {
+	m := &Manifest{
+		Commands: map[string]CommandDef{
+			"encounter_start": {
+				Name:   "encounter start",
+				Params: []ParamDef{{Name: "with", Type: "list<target>"}},
+				Game: CommandPhase{Steps: []GameStep{
+					{Name: "setup", Value: "{ loop('encounter_start', true), add_actor(command.with) }"},
+				}},
+			},
+			"bless": {
+				Name: "bless",
+				Targets: CommandPhase{Steps: []GameStep{
+					{Name: "effects", Value: "{ condition('blessed'), { condition('warded'), 7 } }"},
+				}},
+				Actor: CommandPhase{Steps: []GameStep{
+					{Name: "cost", Value: "{ spend('actions'), spend('spell_slots') }"},
+					{Name: "plain", Value: "{ 1, 2 }"},
+					{Name: "report", Value: "hint(actor_results.cost[1] .. ' ' .. actor_results.cost[2] .. ' ' .. #actor_results.plain)"},
+				}},
+			},
+		},
+	}
+	state := testState()
+	eval, err := NewLuaEvaluator(mockRoll)
+	require.NoError(t, err)
+	defer eval.Close()
+
+	events, err := ExecuteCommand("encounter_start", "GM", nil,
+		map[string]any{"with": []string{"fighter", "goblin"}}, state, m, eval)
+	require.NoError(t, err)
+	require.Len(t, events, 3)
+	assert.IsType(t, &LoopEvent{}, events[0])
+	assert.Equal(t, "fighter", events[1].(*ActorAddedEvent).ActorID)
+	assert.Equal(t, "goblin", events[2].(*ActorAddedEvent).ActorID)
+	for _, e := range events {
+		require.NoError(t, e.Apply(state))
+	}
+	assert.ElementsMatch(t, []string{"fighter", "goblin"}, state.Loops["encounter_start"].Actors)
+
+	events, err = ExecuteCommand("bless", "fighter", []string{"goblin"}, nil, state, m, eval)
+	require.NoError(t, err)
+	for _, e := range events {
+		require.NoError(t, e.Apply(state))
+	}
+	assert.Equal(t, []string{"blessed", "warded"}, state.Entities["goblin"].Conditions)
+	assert.Equal(t, 1, state.Entities["fighter"].Spent["actions"])
+	assert.Equal(t, 1, state.Entities["fighter"].Spent["spell_slots"])
+	assert.Equal(t, "actions spell_slots 2", events[len(events)-1].Message())
+}
+
 func TestHelperFunctions(t *testing.T) {
 	eval, err := NewLuaEvaluator(mockRoll)
 	require.NoError(t, err)
@@ -448,3 +537,22 @@ func TestExecuteCommand_SpendCustomAmount(t *testing.T) {
 	require.NoError(t, evt.Apply(state))
 	assert.Equal(t, 5, actor.Spent["arrows"])
 }
+
+func TestExecutionLimitNamesCommandAndStep(t *testing.T) {
+	eval, err := NewLuaEvaluatorWithLimits(mockRoll, Limits{Timeout: 20 * time.Millisecond})
+	require.NoError(t, err)
+	defer eval.Close()
+
+	m := &Manifest{Commands: map[string]CommandDef{
+		"meditate": {
+			Name: "meditate",
+			Game: CommandPhase{Steps: []GameStep{
+				{Name: "forever", Value: "(function() while true do end end)()"},
+			}},
+		},
+	}}
+
+	_, err = ExecuteCommand("meditate", "GM", nil, nil, NewGameState(), m, eval)
+	require.ErrorIs(t, err, ErrLimitExceeded)
+	assert.ErrorContains(t, err, "meditate: game step 'forever' failed")
+}
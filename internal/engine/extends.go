@@ -0,0 +1,86 @@
+package engine
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// TemplateLookup finds the template entity named by an extends declaration.
+// The returned entity may itself extend another template. shadowed is the number of definitions
+// of the name to skip, most specific first: those of entities already being resolved, so that a
+// world file can extend the SRD template it shadows.
+type TemplateLookup func(name string, shadowed int) (*Entity, error)
+
+// ResolveExtends returns the entity with its extends chain merged in, from the root template
+// down to e. Map sections (classes, stats, resources, spent, proficiencies, statuses, inventory
+// and actions) are merged key by key with the child winning; lists (types, conditions) and the
+// name are replaced only when the child declares them. A child that sets resources.hp without
+// declaring its own classes.hit_dice keeps that hp: the inherited hit dice, which spawned copies
+// would roll instead, are dropped. An entity that extends its own name gets the next definition
+// of that name, e.g. a world goblin extending the SRD goblin; a template cycle, where no further
+// definition is found, is an error.
+func ResolveExtends(e *Entity, lookup TemplateLookup) (*Entity, error) {
+	return resolveExtends(e, lookup, []string{templateKey(e.ID)})
+}
+
+func resolveExtends(e *Entity, lookup TemplateLookup, chain []string) (*Entity, error) {
+	if e.Extends == "" {
+		return e, nil
+	}
+	name := templateKey(e.Extends)
+	shadowed := 0
+	for _, link := range chain {
+		if link == name {
+			shadowed++
+		}
+	}
+
+	template, err := lookup(e.Extends, shadowed)
+	switch {
+	case err != nil && shadowed > 0:
+		return nil, fmt.Errorf("template cycle: %s -> %s", strings.Join(chain, " -> "), name)
+	case err != nil:
+		return nil, fmt.Errorf("entity %s extends %s: %w", e.ID, e.Extends, err)
+	}
+	parent, err := resolveExtends(template, lookup, append(chain, name))
+	if err != nil {
+		return nil, err
+	}
+	return mergeEntity(parent, e), nil
+}
+
+// templateKey normalizes a template name, so that "Giant_Rat" and "giant-rat" name the same one.
+func templateKey(name string) string {
+	return strings.ReplaceAll(strings.ToLower(name), "-", "_")
+}
+
+// mergeEntity overlays child on top of a copy of parent.
+func mergeEntity(parent, child *Entity) *Entity {
+	out := parent.Clone()
+	out.ID = child.ID
+	out.Extends = child.Extends
+	if child.Name != "" {
+		out.Name = child.Name
+	}
+	if len(child.Types) > 0 {
+		out.Types = slices.Clone(child.Types)
+	}
+	if len(child.Conditions) > 0 {
+		out.Conditions = slices.Clone(child.Conditions)
+	}
+	maps.Copy(out.Classes, child.Classes)
+	maps.Copy(out.Stats, child.Stats)
+	maps.Copy(out.Resources, child.Resources)
+	maps.Copy(out.Spent, child.Spent)
+	maps.Copy(out.Proficiencies, child.Proficiencies)
+	maps.Copy(out.Statuses, child.Statuses)
+	maps.Copy(out.Inventory, child.Inventory)
+	maps.Copy(out.Hooks, child.Hooks)
+	maps.Copy(out.Actions, child.Actions)
+	if _, fixedHP := child.Resources["hp"]; fixedHP && child.Classes["hit_dice"] == "" {
+		delete(out.Classes, "hit_dice")
+	}
+	return out
+}
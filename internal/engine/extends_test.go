@@ -0,0 +1,128 @@
+package engine
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func templateLookup(templates map[string]*Entity) TemplateLookup {
+	return func(name string, shadowed int) (*Entity, error) {
+		if e, ok := templates[name]; ok && shadowed == 0 {
+			return e, nil
+		}
+		return nil, fmt.Errorf("unknown template: %s", name)
+	}
+}
+
+func TestResolveExtends_DeepMerge(t *testing.T) {
+	base := NewEntity("humanoid", "Humanoid")
+	base.Types = []string{"humanoid"}
+	base.Stats["str"] = 10
+	base.Stats["dex"] = 10
+	base.Resources["actions"] = 1
+	base.Inventory["rations"] = 3
+
+	bandit := NewEntity("bandit", "Bandit")
+	bandit.Extends = "humanoid"
+	bandit.Stats["dex"] = 12
+	bandit.Resources["hp"] = 11
+	bandit.Proficiencies["stealth"] = 1
+	bandit.Inventory["scimitar"] = 1
+
+	captain := NewEntity("bandit_captain", "")
+	captain.Extends = "bandit"
+	captain.Stats["str"] = 15
+	captain.Resources["hp"] = 65
+	captain.Inventory["rations"] = 5
+
+	lookup := templateLookup(map[string]*Entity{"humanoid": base, "bandit": bandit})
+	e, err := ResolveExtends(captain, lookup)
+	require.NoError(t, err)
+
+	assert.Equal(t, "bandit_captain", e.ID)
+	assert.Equal(t, "Bandit", e.Name)
+	assert.Equal(t, []string{"humanoid"}, e.Types)
+	assert.Equal(t, map[string]int{"str": 15, "dex": 12}, e.Stats)
+	assert.Equal(t, map[string]int{"actions": 1, "hp": 65}, e.Resources)
+	assert.Equal(t, map[string]int{"stealth": 1}, e.Proficiencies)
+	assert.Equal(t, map[string]int{"rations": 5, "scimitar": 1}, e.Inventory)
+
+	// Templates are left untouched
+	assert.Equal(t, 10, base.Stats["str"])
+	assert.Equal(t, 11, bandit.Resources["hp"])
+}
+
+func TestResolveExtends_ChildHPOverridesInheritedHitDice(t *testing.T) {
+	goblin := NewEntity("goblin", "Goblin")
+	goblin.Classes["hit_dice"] = "2d6"
+	goblin.Resources["hp"] = 7
+
+	boss := NewEntity("goblin_boss", "Goblin Boss")
+	boss.Extends = "goblin"
+	boss.Resources["hp"] = 21
+	brute := NewEntity("goblin_brute", "Goblin Brute")
+	brute.Extends = "goblin"
+	brute.Classes["hit_dice"] = "4d6"
+	brute.Resources["hp"] = 14
+	scout := NewEntity("goblin_scout", "Goblin Scout")
+	scout.Extends = "goblin"
+
+	lookup := templateLookup(map[string]*Entity{"goblin": goblin})
+	e, err := ResolveExtends(boss, lookup)
+	require.NoError(t, err)
+	assert.NotContains(t, e.Classes, "hit_dice")
+	e, err = ResolveExtends(brute, lookup)
+	require.NoError(t, err)
+	assert.Equal(t, "4d6", e.Classes["hit_dice"])
+	e, err = ResolveExtends(scout, lookup)
+	require.NoError(t, err)
+	assert.Equal(t, "2d6", e.Classes["hit_dice"])
+	assert.Equal(t, "2d6", goblin.Classes["hit_dice"])
+}
+
+func TestResolveExtends_ShadowedTemplate(t *testing.T) {
+	srd := NewEntity("goblin", "Goblin")
+	srd.Stats["dex"] = 14
+	srd.Resources["hp"] = 7
+	world := NewEntity("goblin", "")
+	world.Extends = "goblin"
+	world.Resources["hp"] = 12
+
+	// The world file comes first, then the SRD template it shadows
+	lookup := func(name string, shadowed int) (*Entity, error) {
+		layers := []*Entity{world, srd}
+		if name != "goblin" || shadowed >= len(layers) {
+			return nil, fmt.Errorf("unknown template: %s", name)
+		}
+		return layers[shadowed], nil
+	}
+	e, err := ResolveExtends(world, lookup)
+	require.NoError(t, err)
+	assert.Equal(t, "Goblin", e.Name)
+	assert.Equal(t, 14, e.Stats["dex"])
+	assert.Equal(t, 12, e.Resources["hp"])
+}
+
+func TestResolveExtends_Errors(t *testing.T) {
+	a := NewEntity("a", "A")
+	a.Extends = "b"
+	b := NewEntity("b", "B")
+	b.Extends = "a"
+
+	_, err := ResolveExtends(a, templateLookup(map[string]*Entity{"a": a, "b": b}))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "template cycle: a -> b -> a")
+
+	orphan := NewEntity("orphan", "Orphan")
+	orphan.Extends = "missing"
+	_, err = ResolveExtends(orphan, templateLookup(nil))
+	assert.ErrorContains(t, err, "unknown template: missing")
+
+	plain := NewEntity("plain", "Plain")
+	e, err := ResolveExtends(plain, templateLookup(nil))
+	require.NoError(t, err)
+	assert.Same(t, plain, e)
+}
@@ -6,7 +6,8 @@ import (
 
 // TriggerHooks inspects the applied event (e.g., TurnStartedEvent) and evaluates
 // any corresponding global or entity-specific hooks, returning the resulting events
-// generated by the hooks plus HookRemovedEvents.
+// generated by the hooks plus the HookFiredEvents or HookRemovedEvents that track
+// each hook's repeat policy.
 func TriggerHooks(state *GameState, trigger Event, eval *LuaEvaluator) ([]Event, error) {
 	var events []Event
 
@@ -27,37 +28,115 @@ func TriggerHooks(state *GameState, trigger Event, eval *LuaEvaluator) ([]Event,
 	}
 
 	for _, hook := range activeHooks {
-		// Evaluate the hook
-		// A hook might be evaluating on behalf of a TargetID
-		var actor, target *Entity
-		actorID := hook.TargetID
-		// Since we don't have the original command context, we'll try to set the context based on TargetID
-		if actorID != "" {
-			actor = state.Entities[actorID]
-			// We might alias target = actor if the hook was declared in targets block,
-			// but we just pass actor and target as the exact same entity to be safe.
-			target = actor
+		if hook.Value == nil {
+			return nil, fmt.Errorf("hook %s is not bound to a manifest definition", hook.Name)
 		}
 
-		ctx := BuildContext(state, actor, target, nil, nil, nil, nil)
+		// Restore the context captured when the hook was created: the issuing actor
+		// and the entity the hook watches (which is the actor itself for actor hooks).
+		actorID := hook.ActorID
+		if actorID == "" {
+			actorID = hook.TargetID
+		}
+		actor := state.Entities[actorID]
+		target := state.Entities[hook.TargetID]
+
+		ctx := BuildContext(state, actor, target, hook.Params, nil, nil, nil)
+		removed := &HookRemovedEvent{TargetID: hook.TargetID, HookName: hook.Name}
+		eval.trace.open(TraceHook, hook.SourceCommand+"."+hook.Name, traceInput(actorID, hook.TargetID, hook.Params))
+
+		if hook.StopWhen != nil {
+			stop, err := eval.Eval(hook.StopWhen, ctx)
+			if err != nil {
+				eval.trace.close(nil, err)
+				return nil, fmt.Errorf("hook %s of command %s: stop condition failed: %w", hook.Name, hook.SourceCommand, err)
+			}
+			if done, _ := stop.(bool); done {
+				eval.trace.close("stopped", nil, removed)
+				events = append(events, removed)
+				continue
+			}
+		}
+
+		eval.hookCancelled = false
 		result, err := eval.Eval(hook.Value, ctx)
 		if err != nil {
-			return nil, fmt.Errorf("hook %s failed: %w", hook.Name, err)
+			eval.trace.close(nil, err)
+			return nil, fmt.Errorf("hook %s of command %s failed: %w", hook.Name, hook.SourceCommand, err)
 		}
 
-		evts, _ := dispatchTaggedResult(result, actorID, actorID, hook.SourceCommand, state)
+		evts, plain := dispatchTaggedResult(result, actorID, hook.TargetID, hook.SourceCommand, state)
 		events = append(events, evts...)
 
-		// Remove the hook after execution
-		events = append(events, &HookRemovedEvent{
-			TargetID: hook.TargetID,
-			HookName: hook.Name,
-		})
+		if eval.hookCancelled || hook.exhausted() {
+			eval.trace.close(plain, nil, append(evts, removed)...)
+			events = append(events, removed)
+			continue
+		}
+		fired := &HookFiredEvent{TargetID: hook.TargetID, HookName: hook.Name}
+		if hook.Repeats == HookRepeatCount {
+			fired.Remaining = hook.Remaining - 1
+		}
+		eval.trace.close(plain, nil, append(evts, fired)...)
+		events = append(events, fired)
 	}
 
 	return events, nil
 }
 
+// BindHooks re-attaches the Lua closures of every hook in the state to its manifest definition,
+// identified by source command, phase and hook name. It must be called after the state is
+// rebuilt from the event log, since closures are not persisted.
+func BindHooks(state *GameState, m *Manifest) error {
+	if err := bindHookMap(state.Hooks, m); err != nil {
+		return err
+	}
+	for _, ent := range state.Entities {
+		if err := bindHookMap(ent.Hooks, m); err != nil {
+			return fmt.Errorf("entity %s: %w", ent.ID, err)
+		}
+	}
+	return nil
+}
+
+func bindHookMap(hooks map[string]Hook, m *Manifest) error {
+	for name, hook := range hooks {
+		def, ok := findHookDef(m, hook)
+		if !ok {
+			return fmt.Errorf("hook %s of command %s (%s phase) is no longer defined in the manifest", hook.Name, hook.SourceCommand, hook.Phase)
+		}
+		hook.Value = def.Value
+		hook.StopWhen = def.StopWhen
+		hooks[name] = hook
+	}
+	return nil
+}
+
+// findHookDef looks up the manifest definition a hook refers to. Hooks recorded without
+// a phase are searched in every phase of their source command.
+func findHookDef(m *Manifest, hook Hook) (HookDef, bool) {
+	cmd, ok := m.Commands[hook.SourceCommand]
+	if !ok {
+		return HookDef{}, false
+	}
+	phases := []string{PhaseGame, PhaseTargets, PhaseActor}
+	if hook.Phase != "" {
+		phases = []string{hook.Phase}
+	}
+	for _, name := range phases {
+		phase := cmd.Phase(name)
+		if phase == nil {
+			continue
+		}
+		for _, def := range phase.Hooks {
+			if def.Name == hook.Name {
+				return def, true
+			}
+		}
+	}
+	return HookDef{}, false
+}
+
 // collectHooks returns all currently registered hooks matching the given types.
 // It checks GameState (global) and all Entities.
 func collectHooks(state *GameState, types []string) []Hook {
@@ -0,0 +1,249 @@
+package engine
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+
+	lua "github.com/yuin/gopher-lua"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultLocale is the locale of the messages the engine ships with.
+const DefaultLocale = "en"
+
+// english holds the engine's own messages by ID. Every other locale falls back to them.
+// Messages are fmt formats; translations can reorder their arguments with %[2]s and the like.
+var english = map[string]string{
+	"event.loop.started":          "%s started",
+	"event.loop.ended":            "%s ended",
+	"event.loop_order":            "%s order set",
+	"event.actor_added":           "%s added to %s",
+	"event.attribute_changed":     "%s.%s.%s changed",
+	"event.spent":                 "%s spent %d %s",
+	"event.condition.added":       "%s is now %s",
+	"event.condition.removed":     "%s is no longer %s",
+	"event.ask":                   "Waiting for %s to respond",
+	"event.dice_rolled":           "%s rolled %s = %d",
+	"event.metadata_changed":      "metadata.%s updated",
+	"event.check.passed":          "%s %s check: passed",
+	"event.check.failed":          "%s %s check: failed",
+	"event.custom":                "custom event: %s",
+	"event.declared":              "%s event",
+	"event.turn_ended":            "%s's turn ended",
+	"event.turn_started":          "%s's turn (turn %d)",
+	"event.round_started":         "round %d started",
+	"event.hook_added":            "Hook %s added to %s",
+	"event.hook_added.global":     "Global hook %s added",
+	"event.hook_removed":          "Hook %s removed",
+	"event.hook_fired":            "Hook %s fired",
+	"event.hook_fired.remaining":  "Hook %s fired (%d remaining)",
+	"event.undo.turn":             "Undo requested to turn %d.",
+	"event.undo.round":            "Undo requested to round %d.",
+	"event.undo.steps":            "Undo requested for %d step(s).",
+	"event.reload":                "Manifest reload requested.",
+	"event.entity_created":        "%s appears",
+	"event.entity_created.hp":     "%s appears (%d hp)",
+	"event.entity_removed":        "%s is removed from the game",
+	"event.spawn":                 "Spawn requested: %d x %s.",
+	"event.secret":                "[secret #%d] %s",
+	"event.secret.kept":           "The GM keeps a secret (#%d).",
+	"event.secret_revealed":       "GM reveals secret #%d: %s",
+	"command.unknown":             "unknown command: %s",
+	"command.gm_only":             "unauthorized: %s can only be executed by the GM",
+	"command.missing_param":       "missing required parameter: %s",
+	"command.empty":               "empty command",
+	"builtin.roll.dice":           "roll requires a 'dice' parameter (e.g., roll dice: 2d6+3)",
+	"builtin.help.list":           "**Available commands:**",
+	"builtin.help.command":        "**%s**: %s\nUsage: %s",
+	"builtin.help.layer":          "Defined in overlay: %s",
+	"builtin.hint.none":           "No command has been executed yet.",
+	"builtin.hint.missing":        "No hint available for '%s'.",
+	"builtin.ask.target":          "ask requires at least one target",
+	"builtin.gm_only":             "only the GM can use '%s'",
+	"builtin.spawn.template":      "spawn requires a template (e.g., spawn goblin count: 4)",
+	"builtin.spawn.count":         "spawn count must be between 1 and %d",
+	"builtin.clone.entity":        "clone requires exactly one entity (e.g., clone of: Goblin_A)",
+	"builtin.clone.count":         "clone count must be between 1 and %d",
+	"builtin.despawn.entity":      "despawn requires at least one entity (e.g., despawn of: Goblin_A)",
+	"builtin.entity_not_found":    "entity %s not found",
+	"builtin.reveal.id":           "reveal id must be a number",
+	"builtin.reveal.revealed":     "secret #%d was already revealed",
+	"builtin.reveal.not_found":    "secret #%d not found",
+	"builtin.reveal.none":         "there are no secrets to reveal",
+	"session.reloaded":            "Manifest reloaded.",
+	"session.reload_failed":       "Manifest reload failed, keeping the previous one: %v",
+	"session.explain.dry_run":     "Dry run: nothing was applied or logged.",
+	"session.explain.unsupported": "%s cannot be explained: it changes the log or the manifest",
+	"command.invalid_params":      "invalid parameters for %s: %w. Usage: %s",
+	"session.undo.done":           "Undid %d event(s). State rewound.",
+	"session.undo.boundary":       "Undid %d event(s) to %s boundary. State rewound.",
+	"session.undo.nothing":        "Nothing to undo.",
+	"session.undo.steps":          "steps must be at least 1",
+	"session.undo.too_many":       "cannot undo %d events: only %d events in the log",
+	"session.undo.boundary_count": "cannot undo %d %s(s): only %d found in the log",
+}
+
+// Catalog holds the messages of one locale by ID. IDs it lacks fall back to the engine's
+// English messages, and unknown IDs to the ID itself.
+type Catalog struct {
+	Locale   string
+	messages map[string]string
+}
+
+// NewCatalog creates a catalog for a locale from its messages.
+func NewCatalog(locale string, messages map[string]string) *Catalog {
+	return &Catalog{Locale: locale, messages: maps.Clone(messages)}
+}
+
+// LoadCatalog reads the locale files at paths into a catalog; later files override earlier
+// ones. Files map message IDs to texts, and nested maps join their keys with dots, so
+// `event: {loop: {started: ...}}` defines event.loop.started. Missing files are skipped.
+func LoadCatalog(locale string, paths ...string) (*Catalog, error) {
+	messages := make(map[string]string)
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read locale file: %w", err)
+		}
+		var tree map[string]any
+		if err := yaml.Unmarshal(data, &tree); err != nil {
+			return nil, fmt.Errorf("failed to parse locale file %s: %w", path, err)
+		}
+		flattenMessages(messages, "", tree)
+	}
+	return NewCatalog(locale, messages), nil
+}
+
+func flattenMessages(out map[string]string, prefix string, tree map[string]any) {
+	for k, v := range tree {
+		id := prefix + k
+		switch v := v.(type) {
+		case map[string]any:
+			flattenMessages(out, id+".", v)
+		case nil:
+		default:
+			out[id] = fmt.Sprint(v)
+		}
+	}
+}
+
+// Lookup returns the catalog's own text for a message ID, without falling back to English.
+func (c *Catalog) Lookup(id string) (string, bool) {
+	if c == nil {
+		return "", false
+	}
+	text, ok := c.messages[id]
+	return text, ok
+}
+
+// Text formats the message with the given ID. A nil catalog formats the English message.
+func (c *Catalog) Text(id string, args ...any) string {
+	format := c.format(id)
+	if len(args) == 0 {
+		return format
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+// Error is Text as an error. Messages may wrap an error argument with %w.
+func (c *Catalog) Error(id string, args ...any) error {
+	format := c.format(id)
+	if len(args) == 0 {
+		return errors.New(format)
+	}
+	return fmt.Errorf(format, args...)
+}
+
+func (c *Catalog) format(id string) string {
+	if format, ok := c.Lookup(id); ok {
+		return format
+	}
+	if format, ok := english[id]; ok {
+		return format
+	}
+	return id
+}
+
+// Localized is implemented by events whose built-in message can be rendered in any locale.
+// Their Message is the English one.
+type Localized interface {
+	MessageIn(c *Catalog) string
+}
+
+// MessageIn returns an event's message in a catalog's locale. Narrations keep the text their
+// formatter wrote; other wrappers are looked through.
+func MessageIn(evt Event, c *Catalog) string {
+	for {
+		switch e := evt.(type) {
+		case *NarratedEvent:
+			return e.Text
+		case Localized:
+			return e.MessageIn(c)
+		}
+		w, ok := evt.(interface{ Unwrap() Event })
+		if !ok {
+			return evt.Message()
+		}
+		evt = w.Unwrap()
+	}
+}
+
+// LocalizedEvent is an event whose message is rendered with a catalog, so that frontends show
+// it in the locale of the session it comes from. It behaves as the event it wraps.
+type LocalizedEvent struct {
+	Event
+	Catalog *Catalog
+}
+
+func (e *LocalizedEvent) Message() string { return MessageIn(e.Event, e.Catalog) }
+
+// Unwrap returns the localized event.
+func (e *LocalizedEvent) Unwrap() Event { return e.Event }
+
+func (e *LocalizedEvent) MarshalJSON() ([]byte, error) { return json.Marshal(e.Event) }
+
+// LocalizeManifest replaces the help, hint and usage texts of the manifest's commands, and the
+// errors of their prereqs, with the catalog's translations: command.<name>.help,
+// command.<name>.hint, command.<name>.usage and command.<name>.prereq.<prereq>. The manifest
+// keeps the catalog, which the engine's messages for its commands are rendered with.
+func LocalizeManifest(m *Manifest, c *Catalog) {
+	m.Catalog = c
+	for key, cmd := range m.Commands {
+		prefix := "command." + key + "."
+		if text, ok := c.Lookup(prefix + "help"); ok {
+			cmd.Help = text
+		}
+		if text, ok := c.Lookup(prefix + "hint"); ok {
+			cmd.Hint = text
+		}
+		if text, ok := c.Lookup(prefix + "usage"); ok {
+			cmd.Error = text
+		}
+		prereqs := make([]PrereqStep, len(cmd.Prereq))
+		for i, p := range cmd.Prereq {
+			if text, ok := c.Lookup(prefix + "prereq." + p.Name); ok {
+				p.Error = text
+			}
+			prereqs[i] = p
+		}
+		cmd.Prereq = prereqs
+		m.Commands[key] = cmd
+	}
+}
+
+// luaTr exposes the catalog to Lua: tr("spell.fizzles", actor.name) -> string
+func (ev *LuaEvaluator) luaTr(L *lua.LState) int {
+	id := L.CheckString(1)
+	args := make([]any, 0, L.GetTop()-1)
+	for i := 2; i <= L.GetTop(); i++ {
+		args = append(args, luaValueToGo(L.Get(i)))
+	}
+	L.Push(lua.LString(ev.catalog.Text(id, args...)))
+	return 1
+}
@@ -0,0 +1,85 @@
+package engine
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCatalog_FallsBackToEnglishAndIDs(t *testing.T) {
+	c := NewCatalog("pt", map[string]string{
+		"event.actor_added": "%[2]s recebe %[1]s",
+	})
+	assert.Equal(t, "goblin recebe encounter", c.Text("event.actor_added", "encounter", "goblin"))
+	assert.Equal(t, "round 2 started", c.Text("event.round_started", 2))
+	assert.Equal(t, "no.such.message", c.Text("no.such.message"))
+
+	var none *Catalog
+	assert.Equal(t, "Nothing to undo.", none.Text("session.undo.nothing"))
+}
+
+func TestLoadCatalog_FlattensAndLayersFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeLuaFiles(t, dir, map[string]string{
+		"world/pt.yaml": `
+event:
+  round_started: "rodada %d começou"
+  condition:
+    added: "%s agora está %s"
+`,
+		"campaign/pt.yaml": `event.round_started: "rodada %d!"`,
+	})
+
+	c, err := LoadCatalog("pt", filepath.Join(dir, "world", "pt.yaml"), filepath.Join(dir, "missing.yaml"), filepath.Join(dir, "campaign", "pt.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, "pt", c.Locale)
+	assert.Equal(t, "rodada 3!", c.Text("event.round_started", 3))
+	assert.Equal(t, "goblin agora está grappled", c.Text("event.condition.added", "goblin", "grappled"))
+
+	writeLuaFiles(t, dir, map[string]string{"bad.yaml": "event: [unclosed"})
+	_, err = LoadCatalog("pt", filepath.Join(dir, "bad.yaml"))
+	assert.ErrorContains(t, err, "failed to parse locale file")
+}
+
+func TestCatalog_TranslatesEventsErrorsAndManifests(t *testing.T) {
+	c := NewCatalog("pt", map[string]string{
+		"event.condition.added":               "%s agora está %s",
+		"command.gm_only":                     "só o mestre pode usar %s",
+		"command.grapple.help":                "Agarra o alvo.",
+		"command.grapple.prereq.check_action": "sem ações",
+		"spell.fizzles":                       "a magia de %s falha",
+	})
+
+	added := &ConditionEvent{ActorID: "goblin", Condition: "grappled", Add: true}
+	assert.Equal(t, "goblin agora está grappled", MessageIn(added, c))
+	assert.Equal(t, "goblin is now grappled", added.Message())
+	assert.Equal(t, "goblin is no longer grappled", MessageIn(&ConditionEvent{ActorID: "goblin", Condition: "grappled"}, c))
+	localized := &LocalizedEvent{Event: &RecordedEvent{Event: added}, Catalog: c}
+	assert.Equal(t, "goblin agora está grappled", localized.Message())
+	narrated := &LocalizedEvent{Event: &NarratedEvent{Event: added, Text: "Goblin is held"}, Catalog: c}
+	assert.Equal(t, "Goblin is held", narrated.Message())
+
+	m := testManifest()
+	LocalizeManifest(m, c)
+	assert.Equal(t, "Agarra o alvo.", m.Commands["grapple"].Help)
+	assert.Equal(t, "Grapple command grapples the target.", m.Commands["grapple"].Hint)
+	assert.Equal(t, "sem ações", m.Commands["grapple"].Prereq[0].Error)
+
+	eval, err := NewLuaEvaluator(mockRoll)
+	require.NoError(t, err)
+	defer eval.Close()
+	eval.SetCatalog(c)
+
+	state := testState()
+	_, err = ExecuteCommand("encounter_start", "fighter", nil, nil, state, m, eval)
+	assert.EqualError(t, err, "só o mestre pode usar encounter_start")
+	state.Entities["fighter"].Spent["actions"] = 1
+	_, err = ExecuteCommand("grapple", "fighter", []string{"goblin"}, map[string]any{"to": "goblin"}, state, m, eval)
+	assert.EqualError(t, err, "sem ações")
+
+	text, err := eval.Eval(`tr("spell.fizzles", "Elara")`, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "a magia de Elara falha", text)
+}
@@ -0,0 +1,333 @@
+package engine
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	lua "github.com/yuin/gopher-lua"
+	"github.com/yuin/gopher-lua/ast"
+	"github.com/yuin/gopher-lua/parse"
+)
+
+// Lint issue severities.
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// LintIssue is a problem found in a manifest. Line is 0 when the position is unknown.
+type LintIssue struct {
+	Severity string
+	File     string
+	Line     int
+	Message  string
+}
+
+func (i LintIssue) String() string {
+	if i.Line > 0 {
+		return fmt.Sprintf("%s:%d: %s: %s", i.File, i.Line, i.Severity, i.Message)
+	}
+	return fmt.Sprintf("%s: %s: %s", i.File, i.Severity, i.Message)
+}
+
+// Known values of manifest fields, as understood by the executor and TriggerHooks.
+var (
+	paramTypes  = []string{"string", "int", "target", "list<target>"}
+	hookTypes   = []string{"next_turn", "next_actor_turn", "next_target_turn", "next_turn_end", "next_actor_turn_end", "next_target_turn_end", "next_round"}
+	hookRepeats = []string{"", HookRepeatOnce, HookRepeatAlways, HookRepeatCount}
+)
+
+var (
+	luaErrorLine   = regexp.MustCompile(`([^\s:]+\.lua)(?::| line:)(\d+)`)
+	loopActiveRef  = regexp.MustCompile(`\bis_([A-Za-z0-9_]+)_active\b`)
+	loopCreateCall = regexp.MustCompile(`\bloop\(\s*([^,)]*)`)
+	quotedName     = regexp.MustCompile(`^["']([^"']*)["']$`)
+)
+
+// LintManifest validates a manifest.lua file. It parses the file, loads it (with require()
+// resolving through modulePaths, or the manifest's directory) and checks the resulting commands,
+// hooks and restrictions. Problems that prevent loading are reported as a single error issue.
+func LintManifest(path string, modulePaths ...string) []LintIssue {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return []LintIssue{{Severity: SeverityError, File: path, Message: err.Error()}}
+	}
+	chunk, err := parse.Parse(strings.NewReader(string(src)), path)
+	if err != nil {
+		return []LintIssue{loadIssue(path, err)}
+	}
+
+	eval, err := NewLuaEvaluator(nil)
+	if err != nil {
+		return []LintIssue{{Severity: SeverityError, File: path, Message: err.Error()}}
+	}
+	defer eval.Close()
+	if len(modulePaths) > 0 {
+		eval.SetModulePaths(modulePaths...)
+	}
+	m, err := eval.LoadManifestLua(path)
+	if err != nil {
+		return []LintIssue{loadIssue(path, err)}
+	}
+
+	l := &linter{path: path, lines: make(map[string]int)}
+	indexChunkLines(l.lines, chunk)
+	l.checkCommands(m)
+	l.checkRestrictions(m)
+	l.checkLoops(append([]string{path}, eval.ModuleFiles()...))
+	return l.issues
+}
+
+// loadIssue turns a parse or load error into an issue, locating it at the innermost position the
+// message names (a failing module rather than the manifest's require call).
+func loadIssue(path string, err error) LintIssue {
+	issue := LintIssue{Severity: SeverityError, File: path, Message: luaErrorMessage(err)}
+	if matches := luaErrorLine.FindAllStringSubmatch(issue.Message, -1); matches != nil {
+		match := matches[len(matches)-1]
+		issue.File = match[1]
+		issue.Line, _ = strconv.Atoi(match[2])
+	}
+	return issue
+}
+
+// linter accumulates the issues of one manifest.
+type linter struct {
+	path   string
+	lines  map[string]int // dotted table path (e.g., "commands.grapple.prereq.1") → line
+	issues []LintIssue
+}
+
+// position is where an issue is reported; an empty file means the manifest itself.
+type position struct {
+	file string
+	line int
+}
+
+func (l *linter) report(severity string, pos position, format string, args ...any) {
+	file := pos.file
+	if file == "" {
+		file = l.path
+	}
+	l.issues = append(l.issues, LintIssue{Severity: severity, File: file, Line: pos.line, Message: fmt.Sprintf(format, args...)})
+}
+
+// at returns the position of the first table path that is known. Tables built outside the
+// manifest's top-level assignments (e.g., in modules) are located by the closure of their
+// formula, if it is one.
+func (l *linter) at(formula any, paths ...string) position {
+	for _, p := range paths {
+		if n, ok := l.lines[p]; ok {
+			return position{line: n}
+		}
+	}
+	if fn, ok := formula.(*lua.LFunction); ok && fn.Proto != nil {
+		return position{file: fn.Proto.SourceName, line: fn.Proto.LineDefined}
+	}
+	return position{}
+}
+
+func (l *linter) checkCommands(m *Manifest) {
+	for _, name := range slices.Sorted(maps.Keys(m.Commands)) {
+		cmd := m.Commands[name]
+		base := "commands." + name
+		cmdPos := l.at(nil, base)
+
+		if isBuiltin(name) {
+			l.report(SeverityWarning, cmdPos, "command %s shadows the built-in command of the same name", name)
+		}
+		if len(cmd.Game.Steps)+len(cmd.Targets.Steps)+len(cmd.Actor.Steps)+len(cmd.Game.Hooks)+len(cmd.Targets.Hooks)+len(cmd.Actor.Hooks) == 0 {
+			l.report(SeverityWarning, cmdPos, "command %s has no steps or hooks and does nothing", name)
+		}
+
+		for i, p := range cmd.Params {
+			pos := l.at(nil, fmt.Sprintf("%s.params.%d", base, i+1), base)
+			if !isSet(p.Name) {
+				l.report(SeverityError, pos, "command %s: parameter #%d has no name", name, i+1)
+			}
+			if !slices.Contains(paramTypes, p.Type) {
+				l.report(SeverityWarning, pos, "command %s: parameter %s has unknown type %q (expected one of %s)", name, p.Name, p.Type, strings.Join(paramTypes, ", "))
+			}
+		}
+
+		for i, pr := range cmd.Prereq {
+			pos := l.at(pr.Value, fmt.Sprintf("%s.prereq.%d", base, i+1), base)
+			label := stepLabel("prereq", pr.Name, i)
+			if !isSet(pr.Name) {
+				l.report(SeverityWarning, pos, "command %s: %s has no name", name, label)
+			}
+			l.checkFormula(name, label, pr.Value, pos)
+			if !isSet(pr.Error) {
+				l.report(SeverityWarning, pos, "command %s: %s has no error message to show when it fails", name, label)
+			}
+		}
+
+		for _, phaseName := range []string{PhaseGame, PhaseTargets, PhaseActor} {
+			l.checkPhase(name, phaseName, cmd.Phase(phaseName))
+		}
+	}
+}
+
+func (l *linter) checkPhase(cmdName, phaseName string, phase *CommandPhase) {
+	base := fmt.Sprintf("commands.%s.%s", cmdName, phaseName)
+	seen := make(map[string]bool)
+	for i, step := range phase.Steps {
+		pos := l.at(step.Value, fmt.Sprintf("%s.steps.%d", base, i+1), fmt.Sprintf("%s.%d", base, i+1), base)
+		label := stepLabel(phaseName+" step", step.Name, i)
+		if !isSet(step.Name) {
+			l.report(SeverityWarning, pos, "command %s: %s has no name, so later steps cannot read its result", cmdName, label)
+		} else if seen[step.Name] {
+			l.report(SeverityWarning, pos, "command %s: %s reuses the name of an earlier step, hiding its result", cmdName, label)
+		}
+		seen[step.Name] = true
+		l.checkFormula(cmdName, label, step.Value, pos)
+	}
+
+	for i, hook := range phase.Hooks {
+		pos := l.at(hook.Value, fmt.Sprintf("%s.hooks.%d", base, i+1), base)
+		label := stepLabel(phaseName+" hook", hook.Name, i)
+		if !isSet(hook.Name) {
+			l.report(SeverityError, pos, "command %s: %s has no name", cmdName, label)
+		}
+		if !slices.Contains(hookTypes, hook.Type) {
+			l.report(SeverityError, pos, "command %s: %s has unsupported type %q, so it never fires (expected one of %s)", cmdName, label, hook.Type, strings.Join(hookTypes, ", "))
+		}
+		if !slices.Contains(hookRepeats, hook.Repeats) {
+			l.report(SeverityError, pos, "command %s: %s has unsupported repeats %q (expected \"once\", \"always\" or a number)", cmdName, label, hook.Repeats)
+		} else if hook.Repeats == HookRepeatCount && hook.Count < 1 {
+			l.report(SeverityError, pos, "command %s: %s repeats %d times, so it never fires", cmdName, label, hook.Count)
+		}
+		l.checkFormula(cmdName, label, hook.Value, pos)
+		if hook.StopWhen != nil {
+			l.checkFormula(cmdName, label+" stop_when", hook.StopWhen, pos)
+		}
+	}
+}
+
+// checkFormula reports missing formulas and string formulas that do not compile.
+func (l *linter) checkFormula(cmdName, label string, formula any, pos position) {
+	switch f := formula.(type) {
+	case nil:
+		l.report(SeverityError, pos, "command %s: %s has no value (expected a string expression, a function or a boolean)", cmdName, label)
+	case string:
+		if _, err := parse.Parse(strings.NewReader("return "+f), label); err != nil {
+			l.report(SeverityError, pos, "command %s: %s has an invalid expression %q: %s", cmdName, label, f, luaErrorMessage(err))
+		}
+	}
+}
+
+func (l *linter) checkRestrictions(m *Manifest) {
+	known := func(name string) bool {
+		_, ok := m.Commands[name]
+		return ok || isBuiltin(name)
+	}
+	for i, name := range m.Restrictions.GMCommands {
+		if !known(name) {
+			l.report(SeverityError, l.at(nil, fmt.Sprintf("restrictions.gm_commands.%d", i+1), "restrictions"), "restrictions.gm_commands names unknown command %q", name)
+		}
+	}
+	for i, name := range m.Restrictions.Adjudication.Commands {
+		if !known(name) {
+			l.report(SeverityError, l.at(nil, fmt.Sprintf("restrictions.adjudication.commands.%d", i+1), "restrictions"), "restrictions.adjudication.commands names unknown command %q", name)
+		}
+	}
+}
+
+// checkLoops warns about is_<name>_active references to loops that no loop() call creates.
+// Such calls silently return false. Loops created under a computed name are not known.
+func (l *linter) checkLoops(files []string) {
+	created := make(map[string]bool)
+	type ref struct {
+		file string
+		line int
+		name string
+	}
+	var refs []ref
+	for _, file := range files {
+		src, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		for n, text := range strings.Split(string(src), "\n") {
+			if code, _, _ := strings.Cut(text, "--"); code != "" {
+				text = code
+			}
+			for _, match := range loopCreateCall.FindAllStringSubmatch(text, -1) {
+				quoted := quotedName.FindStringSubmatch(strings.TrimSpace(match[1]))
+				if quoted == nil {
+					continue
+				}
+				created[quoted[1]] = true
+			}
+			for _, match := range loopActiveRef.FindAllStringSubmatch(text, -1) {
+				refs = append(refs, ref{file: file, line: n + 1, name: match[1]})
+			}
+		}
+	}
+	for _, r := range refs {
+		if !created[r.name] {
+			l.report(SeverityWarning, position{file: r.file, line: r.line}, "is_%s_active refers to loop %q, which no loop() call creates; it is always false", r.name, r.name)
+		}
+	}
+}
+
+// stepLabel names a step for messages: `game step "roll"`, or `game step #2` when unnamed.
+func stepLabel(kind, name string, i int) string {
+	if isSet(name) {
+		return fmt.Sprintf("%s %q", kind, name)
+	}
+	return fmt.Sprintf("%s #%d", kind, i+1)
+}
+
+// isSet reports whether a string read from Lua was present (missing fields read as "nil").
+func isSet(s string) bool {
+	return s != "" && s != "nil"
+}
+
+// indexChunkLines records the line of every field of the tables assigned to globals at the top
+// level of a chunk, keyed by dotted path (array items by their 1-based position).
+func indexChunkLines(lines map[string]int, chunk []ast.Stmt) {
+	for _, stmt := range chunk {
+		assign, ok := stmt.(*ast.AssignStmt)
+		if !ok {
+			continue
+		}
+		for i, lhs := range assign.Lhs {
+			ident, ok := lhs.(*ast.IdentExpr)
+			if !ok || i >= len(assign.Rhs) {
+				continue
+			}
+			lines[ident.Value] = assign.Rhs[i].Line()
+			if tbl, ok := assign.Rhs[i].(*ast.TableExpr); ok {
+				indexTableLines(lines, ident.Value, tbl)
+			}
+		}
+	}
+}
+
+func indexTableLines(lines map[string]int, prefix string, tbl *ast.TableExpr) {
+	pos := 0
+	for _, field := range tbl.Fields {
+		var key string
+		switch k := field.Key.(type) {
+		case nil:
+			pos++
+			key = strconv.Itoa(pos)
+		case *ast.StringExpr:
+			key = k.Value
+		case *ast.NumberExpr:
+			key = k.Value
+		default:
+			continue
+		}
+		path := prefix + "." + key
+		lines[path] = field.Value.Line()
+		if sub, ok := field.Value.(*ast.TableExpr); ok {
+			indexTableLines(lines, path, sub)
+		}
+	}
+}
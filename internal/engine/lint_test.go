@@ -0,0 +1,148 @@
+package engine
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func lintSource(t *testing.T, files map[string]string) []LintIssue {
+	t.Helper()
+	dir := t.TempDir()
+	writeLuaFiles(t, dir, files)
+	return LintManifest(filepath.Join(dir, "manifest.lua"))
+}
+
+// findIssue returns the first issue whose message contains text.
+func findIssue(issues []LintIssue, text string) *LintIssue {
+	for i := range issues {
+		if strings.Contains(issues[i].Message, text) {
+			return &issues[i]
+		}
+	}
+	return nil
+}
+
+func TestLintManifest_ReportsProblemsWithLines(t *testing.T) {
+	issues := lintSource(t, map[string]string{
+		"manifest.lua": `commands = {
+    grapple = {
+        name = "grapple",
+        params = {
+            { name = "target", type = "creature" },
+        },
+        prereq = {
+            { name = "in_combat", value = "is_battle_active", error = "not in combat" },
+        },
+        game = {
+            steps = {
+                { name = "check" },
+                { name = "roll", value = "roll('1d20' +" },
+            },
+            hooks = {
+                { name = "escape", type = "next_turns", value = "true" },
+            },
+        },
+    },
+}
+restrictions = {
+    gm_commands = { "grapple", "smite" },
+}
+`,
+	})
+
+	cases := []struct {
+		text     string
+		severity string
+		line     int
+	}{
+		{`parameter target has unknown type "creature"`, SeverityWarning, 5},
+		{`game step "check" has no value`, SeverityError, 12},
+		{`game step "roll" has an invalid expression`, SeverityError, 13},
+		{`game hook "escape" has unsupported type "next_turns"`, SeverityError, 16},
+		{`gm_commands names unknown command "smite"`, SeverityError, 22},
+		{`is_battle_active refers to loop "battle"`, SeverityWarning, 8},
+	}
+	for _, c := range cases {
+		issue := findIssue(issues, c.text)
+		if assert.NotNil(t, issue, "missing issue %q in %v", c.text, issues) {
+			assert.Equal(t, c.severity, issue.Severity, c.text)
+			assert.Equal(t, c.line, issue.Line, c.text)
+			assert.Equal(t, "manifest.lua", filepath.Base(issue.File), c.text)
+		}
+	}
+	assert.Len(t, issues, len(cases))
+}
+
+func TestLintManifest_ChecksLoopsPastComputedNames(t *testing.T) {
+	issues := lintSource(t, map[string]string{
+		"manifest.lua": `local kind = "chase"
+local function begin() return loop(kind .. "_round", "start") end
+local function fight() return loop("battle", "start") end
+commands = {
+    flee = {
+        name = "flee",
+        prereq = { { name = "fleeing", value = "is_escape_active", error = "no escape" } },
+        game = { steps = { { name = "go", value = "is_battle_active" } } },
+    },
+}
+`,
+	})
+	issue := findIssue(issues, "is_escape_active")
+	require.NotNil(t, issue, "%v", issues)
+	assert.Equal(t, 7, issue.Line)
+	assert.Nil(t, findIssue(issues, "is_battle_active"))
+}
+
+func TestLintManifest_ReportsLoadErrors(t *testing.T) {
+	issues := lintSource(t, map[string]string{
+		"manifest.lua": "commands = {\n    broken = {\n        name = \"broken\"\n        help = \"missing comma\",\n    },\n}\n",
+	})
+	require.Len(t, issues, 1)
+	assert.Equal(t, SeverityError, issues[0].Severity)
+	assert.Equal(t, 4, issues[0].Line)
+	assert.Contains(t, issues[0].String(), "manifest.lua:4: error:")
+
+	issues = lintSource(t, map[string]string{
+		"manifest.lua": `local rules = require("rules")`,
+		"rules.lua":    "local x = nil\nreturn x.field\n",
+	})
+	require.Len(t, issues, 1)
+	assert.Equal(t, "rules.lua", filepath.Base(issues[0].File))
+	assert.Equal(t, 2, issues[0].Line)
+}
+
+func TestLintManifest_LocatesStepsDefinedInModules(t *testing.T) {
+	issues := lintSource(t, map[string]string{
+		"manifest.lua": `commands = { dodge = require("dodge") }`,
+		"dodge.lua": `return {
+    name = "dodge",
+    game = {
+        steps = {
+            { name = "apply", value = function(ctx)
+                return true
+            end },
+        },
+        hooks = {
+            { name = "expire", type = "never", value = function(ctx) return true end },
+        },
+    },
+}
+`,
+	})
+	issue := findIssue(issues, `unsupported type "never"`)
+	require.NotNil(t, issue, "%v", issues)
+	assert.Equal(t, "dodge.lua", filepath.Base(issue.File))
+	assert.Equal(t, 10, issue.Line)
+}
+
+func TestLintManifest_AcceptsTheBundledManifests(t *testing.T) {
+	for _, path := range []string{"../../test/manifest.lua", "../../world/dnd5e/manifest.lua"} {
+		for _, issue := range LintManifest(path) {
+			assert.NotEqual(t, SeverityError, issue.Severity, "%s: %s", path, issue)
+		}
+	}
+}
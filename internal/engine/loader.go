@@ -30,6 +30,8 @@ func LoadManifest(path string) (*Manifest, error) {
 
 // LoadEntity reads and parses a character or monster YAML file into an Entity struct.
 // After loading, all nil maps are initialized to empty maps to prevent nil-map panics.
+// An extends declaration is kept as is, since its template may live in other data directories
+// or the SRD: callers merge it in with ResolveExtends, as the session does for every entity file.
 func LoadEntity(path string) (*Entity, error) {
 	f, err := os.Open(path)
 	if err != nil {
@@ -42,7 +44,12 @@ func LoadEntity(path string) (*Entity, error) {
 		return nil, fmt.Errorf("failed to decode entity %s: %w", path, err)
 	}
 
-	// Ensure all maps are initialized
+	normalizeEntity(&e)
+	return &e, nil
+}
+
+// normalizeEntity initializes all nil maps and slices of an entity.
+func normalizeEntity(e *Entity) {
 	if e.Types == nil {
 		e.Types = make([]string, 0)
 	}
@@ -70,6 +77,10 @@ func LoadEntity(path string) (*Entity, error) {
 	if e.Inventory == nil {
 		e.Inventory = make(map[string]int)
 	}
-
-	return &e, nil
+	if e.Hooks == nil {
+		e.Hooks = make(map[string]Hook)
+	}
+	if e.Actions == nil {
+		e.Actions = make(map[string]Action)
+	}
 }
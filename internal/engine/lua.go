@@ -1,9 +1,15 @@
 package engine
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"maps"
 	"math/rand"
+	"path/filepath"
+	"runtime/metrics"
 	"strings"
+	"time"
 
 	lua "github.com/yuin/gopher-lua"
 )
@@ -11,20 +17,91 @@ import (
 // RollFunc is a function that evaluates a dice expression (e.g., "1d20") and returns the total.
 type RollFunc func(dice string) int
 
+// Limits bounds what a single formula evaluation may use, so that a runaway manifest formula
+// fails with an error instead of freezing the session. Loading the manifest and its overlays
+// is bounded the same way.
+//
+// The memory limit is approximate and process-wide: Lua allocations are not counted one by
+// one, so the growth of the whole heap is measured instead, garbage and the allocations of
+// other goroutines (the Telegram bot, the manifest watcher) included. It is only measured for
+// evaluations that run longer than memoryCheckInterval, from their size at that point, so it
+// is a guard against runaway formulas rather than a quota.
+type Limits struct {
+	Timeout   time.Duration // wall-clock time per evaluation; 0 disables the deadline
+	CallDepth int           // maximum Lua call depth, which stops runaway recursion
+	StackSize int           // maximum number of values on the Lua stack
+	Memory    int           // bytes the process heap may grow by during an evaluation; 0 disables the check
+}
+
+// DefaultLimits are the limits used by NewLuaEvaluator.
+var DefaultLimits = Limits{
+	Timeout:   2 * time.Second,
+	CallDepth: 200,
+	StackSize: 256 * 1024,
+	Memory:    256 << 20,
+}
+
+// ErrLimitExceeded is wrapped by evaluation errors caused by exceeding the evaluator's Limits.
+var ErrLimitExceeded = errors.New("execution limit exceeded")
+
+// errTimedOut and errOutOfMemory are the causes with which bound stops an evaluation.
+var (
+	errTimedOut    = errors.New("timed out")
+	errOutOfMemory = errors.New("out of memory")
+)
+
+// memoryCheckInterval is how long an evaluation runs before the heap is measured, and then how
+// often it is measured again.
+const memoryCheckInterval = 5 * time.Millisecond
+
+// repTooLarge is the error raised by string.rep for results over the memory limit.
+const repTooLarge = "string.rep result larger than the memory limit"
+
 // LuaEvaluator wraps a GopherLua environment configured for manifest formula evaluation.
 type LuaEvaluator struct {
 	L        *lua.LState
 	rollFunc RollFunc
+	limits   Limits
+
+	// hookCancelled is set by cancel_hook() while a hook body runs.
+	hookCancelled bool
+
+	// reducing is set while an event reducer runs, which must not roll; see reducer.go.
+	reducing bool
+
+	// catalog renders tr(); nil renders the English messages.
+	catalog *Catalog
+
+	// trace records evaluations for explain, when set; see trace.go.
+	trace *Trace
+
+	// modulePaths, modules and loading back the sandboxed require(); see require.go.
+	modulePaths []string
+	modules     map[string]lua.LValue
+	moduleFiles []string
+	loading     map[string]bool
+
+	// dataSource and records back srd(); see srd.go.
+	dataSource DataSource
+	records    map[[2]string]map[string]any
 }
 
-// NewLuaEvaluator creates a sandboxed Lua environment.
+// NewLuaEvaluator creates a sandboxed Lua environment with the DefaultLimits.
 func NewLuaEvaluator(rollFunc RollFunc) (*LuaEvaluator, error) {
+	return NewLuaEvaluatorWithLimits(rollFunc, DefaultLimits)
+}
+
+// NewLuaEvaluatorWithLimits creates a sandboxed Lua environment whose evaluations are bounded by limits.
+func NewLuaEvaluatorWithLimits(rollFunc RollFunc, limits Limits) (*LuaEvaluator, error) {
 	if rollFunc == nil {
 		rollFunc = defaultRoll
 	}
 
 	L := lua.NewState(lua.Options{
-		SkipOpenLibs: true, // We manually open only safe libs
+		SkipOpenLibs:    true, // We manually open only safe libs
+		CallStackSize:   limits.CallDepth,
+		RegistrySize:    min(lua.RegistrySize, limits.StackSize),
+		RegistryMaxSize: limits.StackSize,
 	})
 
 	// Open safe standard libraries
@@ -46,11 +123,32 @@ func NewLuaEvaluator(rollFunc RollFunc) (*LuaEvaluator, error) {
 			return nil, fmt.Errorf("failed to load lua lib %s: %w", pair.n, err)
 		}
 	}
+	if limits.Memory > 0 {
+		L.SetField(L.GetGlobal(lua.StringLibName), "rep", L.NewFunction(func(L *lua.LState) int {
+			str, n := L.CheckString(1), L.CheckInt(2)
+			if len(str) > 0 && n > limits.Memory/len(str) {
+				L.RaiseError(repTooLarge)
+			}
+			L.Push(lua.LString(strings.Repeat(str, max(n, 0))))
+			return 1
+		}))
+	}
 
-	ev := &LuaEvaluator{L: L, rollFunc: rollFunc}
+	ev := &LuaEvaluator{
+		L:        L,
+		rollFunc: rollFunc,
+		limits:   limits,
+		modules:  make(map[string]lua.LValue),
+		loading:  make(map[string]bool),
+		records:  make(map[[2]string]map[string]any),
+	}
 
 	// Register Go functions
 	L.SetGlobal("roll", L.NewFunction(ev.luaRoll))
+	L.SetGlobal("cancel_hook", L.NewFunction(ev.luaCancelHook))
+	L.SetGlobal("tr", L.NewFunction(ev.luaTr))
+	L.SetGlobal("require", L.NewFunction(ev.luaRequire))
+	L.SetGlobal("srd", L.NewFunction(ev.luaSRD))
 
 	// Register event helper functions — each returns a tagged table { _event = "...", ... }
 	registerEventHelpers(L)
@@ -59,7 +157,6 @@ func NewLuaEvaluator(rollFunc RollFunc) (*LuaEvaluator, error) {
 	mt := L.NewTable()
 	L.SetField(mt, "__index", L.NewFunction(func(L2 *lua.LState) int {
 		key := L2.CheckString(2)
-		fmt.Printf("[DEBUG] __index called for key: %s\n", key)
 		if strings.HasPrefix(key, "is_") && strings.HasSuffix(key, "_active") {
 			L2.Push(L2.NewFunction(func(L3 *lua.LState) int {
 				L3.Push(lua.LBool(false))
@@ -82,12 +179,32 @@ func (ev *LuaEvaluator) Close() {
 
 // luaRoll exposes the rollFunc to Lua scripts: roll("1d20") -> number
 func (ev *LuaEvaluator) luaRoll(L *lua.LState) int {
-	dice := L.CheckString(1)
-	result := ev.rollFunc(dice)
-	L.Push(lua.LNumber(result))
+	if ev.reducing {
+		L.RaiseError("roll() cannot be used in an event reducer, which runs again on every replay; roll in the command and pass the result in the payload")
+	}
+	L.Push(lua.LNumber(ev.roll(L.CheckString(1))))
 	return 1
 }
 
+// roll rolls dice with the rollFunc, recording the roll in the trace.
+func (ev *LuaEvaluator) roll(dice string) int {
+	result := ev.rollFunc(dice)
+	ev.trace.add(TraceRoll, dice, result)
+	return result
+}
+
+// SetCatalog selects the catalog tr() renders messages with.
+func (ev *LuaEvaluator) SetCatalog(c *Catalog) {
+	ev.catalog = c
+}
+
+// luaCancelHook lets a hook body stop further firings: cancel_hook()
+// Outside of a hook evaluation it has no effect.
+func (ev *LuaEvaluator) luaCancelHook(L *lua.LState) int {
+	ev.hookCancelled = true
+	return 0
+}
+
 // registerEventHelpers registers typed Lua functions that return tagged tables.
 func registerEventHelpers(L *lua.LState) {
 	// loop(name, active) -> { _event = "loop", name = name, active = active }
@@ -245,7 +362,108 @@ func registerEventHelpers(L *lua.LState) {
 }
 
 // Eval evaluates a Lua expression (string) or closure (*lua.LFunction) against the given context.
-func (ev *LuaEvaluator) Eval(formula any, ctx map[string]any) (any, error) {
+// Closures are called with args, converted to Lua values; expressions ignore them.
+// An evaluation that runs past the evaluator's timeout, grows the heap past its memory limit or
+// overflows its stacks fails with an error wrapping ErrLimitExceeded.
+func (ev *LuaEvaluator) Eval(formula any, ctx map[string]any, args ...any) (any, error) {
+	defer ev.bound()()
+
+	result, err := ev.eval(formula, ctx, args)
+	if err != nil {
+		return nil, ev.limitError(err)
+	}
+	return result, nil
+}
+
+// doFile runs a Lua file under the evaluator's limits, like an evaluation.
+func (ev *LuaEvaluator) doFile(path string) error {
+	defer ev.bound()()
+	if err := ev.L.DoFile(path); err != nil {
+		return ev.limitError(err)
+	}
+	return nil
+}
+
+// bound stops the Lua code run until the returned function is called once it runs past the
+// evaluator's timeout or grows the heap by more than its memory limit. Both are timers, so an
+// evaluation that ends quickly starts no goroutine; see Limits for how memory is measured.
+func (ev *LuaEvaluator) bound() (release func()) {
+	if ev.limits.Timeout <= 0 && ev.limits.Memory <= 0 {
+		return func() {}
+	}
+	ctx, cancel := context.WithCancelCause(context.Background())
+	var timers []*time.Timer
+	if ev.limits.Timeout > 0 {
+		timers = append(timers, time.AfterFunc(ev.limits.Timeout, func() { cancel(errTimedOut) }))
+	}
+	if limit := uint64(ev.limits.Memory); limit > 0 {
+		timers = append(timers, time.AfterFunc(memoryCheckInterval, func() { watchHeap(ctx, cancel, limit) }))
+	}
+	ev.L.SetContext(ctx)
+	return func() {
+		ev.L.RemoveContext()
+		for _, t := range timers {
+			t.Stop()
+		}
+		cancel(nil)
+	}
+}
+
+// watchHeap cancels ctx with errOutOfMemory once the heap grows by more than limit bytes from
+// its size when the watch starts, measuring it every memoryCheckInterval until ctx is done.
+func watchHeap(ctx context.Context, cancel context.CancelCauseFunc, limit uint64) {
+	limit += heapBytes()
+	ticker := time.NewTicker(memoryCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if heapBytes() > limit {
+				cancel(errOutOfMemory)
+				return
+			}
+		}
+	}
+}
+
+// heapBytes returns the bytes occupied by heap objects, live or not yet collected.
+func heapBytes() uint64 {
+	sample := []metrics.Sample{{Name: "/memory/classes/heap/objects:bytes"}}
+	metrics.Read(sample)
+	return sample[0].Value.Uint64()
+}
+
+// limitError wraps ErrLimitExceeded into errors caused by the evaluator's limits.
+func (ev *LuaEvaluator) limitError(err error) error {
+	if ctx := ev.L.Context(); ctx != nil && ctx.Err() != nil {
+		if context.Cause(ctx) == errOutOfMemory {
+			return fmt.Errorf("%w: formula used more than %d bytes of memory", ErrLimitExceeded, ev.limits.Memory)
+		}
+		return fmt.Errorf("%w: formula ran longer than %s", ErrLimitExceeded, ev.limits.Timeout)
+	}
+	msg := err.Error()
+	if strings.Contains(msg, repTooLarge) {
+		return fmt.Errorf("%w: formula used more than %d bytes of memory", ErrLimitExceeded, ev.limits.Memory)
+	}
+	if strings.Contains(msg, "stack overflow") || strings.Contains(msg, "registry overflow") {
+		return fmt.Errorf("%w: formula recursed too deeply or used too much stack: %s", ErrLimitExceeded, luaErrorMessage(err))
+	}
+	return err
+}
+
+// luaErrorMessage returns a Lua error without its stack traceback, which the caller's own
+// error reports again.
+func luaErrorMessage(err error) string {
+	var apiErr *lua.ApiError
+	if errors.As(err, &apiErr) && apiErr.Object != nil {
+		return apiErr.Object.String()
+	}
+	return err.Error()
+}
+
+func (ev *LuaEvaluator) eval(formula any, ctx map[string]any, args []any) (any, error) {
 	// Inject the context into the Lua globals
 	for k, v := range ctx {
 		ev.L.SetGlobal(k, goValueToLua(ev.L, v))
@@ -261,29 +479,30 @@ func (ev *LuaEvaluator) Eval(formula any, ctx map[string]any) (any, error) {
 	switch f := formula.(type) {
 	case string:
 		// Option A: Evaluate string formula
-		script := "return type(is_encounter_start_active) .. ' : ' .. tostring(is_encounter_start_active)\n"
-		if err := ev.L.DoString(script); err == nil {
-			lv := ev.L.Get(-1)
-			ev.L.Pop(1)
-			fmt.Printf("[DEBUG] Evaluating script: %q, type of is_encounter_start_active: %s\n", f, lv.String())
-		}
-
-		script = "return " + f
+		script := "return " + f
+		top := ev.L.GetTop()
 		if err := ev.L.DoString(script); err != nil {
 			return nil, fmt.Errorf("Lua eval error: %w", err)
 		}
-		// Extract result (the return value is on top of the stack)
+		// Extract result (the return value is on top of the stack, if any)
+		if ev.L.GetTop() == top {
+			return nil, nil
+		}
 		lv := ev.L.Get(-1)
-		ev.L.Pop(1)
+		ev.L.SetTop(top)
 		return luaValueToGo(lv), nil
 
 	case *lua.LFunction:
 		// Option B: Call the closure
+		luaArgs := make([]lua.LValue, len(args))
+		for i, arg := range args {
+			luaArgs[i] = goValueToLua(ev.L, arg)
+		}
 		if err := ev.L.CallByParam(lua.P{
 			Fn:      f,
 			NRet:    1,
 			Protect: true,
-		}); err != nil {
+		}, luaArgs...); err != nil {
 			return nil, fmt.Errorf("Lua call error: %w", err)
 		}
 		lv := ev.L.Get(-1)
@@ -300,27 +519,49 @@ func (ev *LuaEvaluator) Eval(formula any, ctx map[string]any) (any, error) {
 }
 
 // LoadManifestLua reads and executes a manifest.lua file, extracting the commands and restrictions.
+// The overlay files run after it, in order, and can change its globals; see loadOverlays.
 // NOTE: This must be called at engine startup to populate the globals.
-func (ev *LuaEvaluator) LoadManifestLua(path string) (*Manifest, error) {
+func (ev *LuaEvaluator) LoadManifestLua(path string, overlays ...string) (*Manifest, error) {
 	m := &Manifest{
 		Commands: make(map[string]CommandDef),
 	}
 
-	if err := ev.L.DoFile(path); err != nil {
+	if len(ev.modulePaths) == 0 {
+		ev.SetModulePaths(filepath.Dir(path))
+	}
+	if err := ev.doFile(path); err != nil {
 		return nil, fmt.Errorf("failed to load manifest.lua: %w", err)
 	}
+	layers, err := ev.loadOverlays(overlays)
+	if err != nil {
+		return nil, err
+	}
 
 	// Read commands table
 	cmdsVal := ev.L.GetGlobal("commands")
 	if cmdsTbl, ok := cmdsVal.(*lua.LTable); ok {
+		var parseErr error
 		cmdsTbl.ForEach(func(k, v lua.LValue) {
 			cmdName := k.String()
 			// v is a table representing CommandDef
-			if t, ok := v.(*lua.LTable); ok {
-				cmdDef := parseCommandDefFromLua(t)
-				m.Commands[cmdName] = cmdDef
+			t, ok := v.(*lua.LTable)
+			if !ok || parseErr != nil {
+				return
 			}
+			cmdDef, err := parseCommandDefFromLua(t)
+			if err != nil {
+				parseErr = fmt.Errorf("commands.%s: %w", cmdName, err)
+				return
+			}
+			cmdDef.Layer = layers[cmdName]
+			if format, ok := t.RawGetString("format").(*lua.LTable); ok {
+				cmdDef.Format, parseErr = parseFormattersFromLua("commands."+cmdName+".format", format)
+			}
+			m.Commands[cmdName] = cmdDef
 		})
+		if parseErr != nil {
+			return nil, parseErr
+		}
 	} else {
 		return nil, fmt.Errorf("manifest.lua must define a 'commands' table")
 	}
@@ -331,10 +572,33 @@ func (ev *LuaEvaluator) LoadManifestLua(path string) (*Manifest, error) {
 		m.Restrictions = parseRestrictionsFromLua(resTbl)
 	}
 
+	// Read visibility table
+	if visTbl, ok := ev.L.GetGlobal("visibility").(*lua.LTable); ok {
+		m.Visibility = parseVisibilityFromLua(visTbl)
+	}
+
+	// Read events table
+	if evtTbl, ok := ev.L.GetGlobal("events").(*lua.LTable); ok {
+		events, err := parseEventsFromLua(evtTbl)
+		if err != nil {
+			return nil, err
+		}
+		m.Events = events
+	}
+
+	// Read formatters table
+	if fmtTbl, ok := ev.L.GetGlobal("formatters").(*lua.LTable); ok {
+		formatters, err := parseFormattersFromLua("formatters", fmtTbl)
+		if err != nil {
+			return nil, err
+		}
+		m.Formatters = formatters
+	}
+
 	return m, nil
 }
 
-func parseCommandDefFromLua(t *lua.LTable) CommandDef {
+func parseCommandDefFromLua(t *lua.LTable) (CommandDef, error) {
 	def := CommandDef{}
 	if name := t.RawGetString("name"); name != lua.LNil {
 		def.Name = name.String()
@@ -366,11 +630,18 @@ func parseCommandDefFromLua(t *lua.LTable) CommandDef {
 	}
 
 	def.Prereq = parsePrereqStepsFromLua(t.RawGetString("prereq"))
-	def.Game = parseCommandPhaseFromLua(t.RawGetString("game"))
-	def.Targets = parseCommandPhaseFromLua(t.RawGetString("targets"))
-	def.Actor = parseCommandPhaseFromLua(t.RawGetString("actor"))
+	var err error
+	if def.Game, err = parseCommandPhaseFromLua(t.RawGetString("game")); err != nil {
+		return def, err
+	}
+	if def.Targets, err = parseCommandPhaseFromLua(t.RawGetString("targets")); err != nil {
+		return def, err
+	}
+	if def.Actor, err = parseCommandPhaseFromLua(t.RawGetString("actor")); err != nil {
+		return def, err
+	}
 
-	return def
+	return def, nil
 }
 
 func parsePrereqStepsFromLua(val lua.LValue) []PrereqStep {
@@ -383,15 +654,7 @@ func parsePrereqStepsFromLua(val lua.LValue) []PrereqStep {
 					Name:  stepTbl.RawGetString("name").String(),
 					Error: stepTbl.RawGetString("error").String(),
 				}
-				f := stepTbl.RawGetString("value")
-				if str, ok := f.(lua.LString); ok {
-					ps.Value = string(str)
-				} else if fn, ok := f.(*lua.LFunction); ok {
-					ps.Value = fn
-				} else if bl, ok := f.(lua.LBool); ok {
-					ps.Value = bool(bl)
-				}
-
+				ps.Value = parseFormulaFromLua(stepTbl.RawGetString("value"))
 				steps = append(steps, ps)
 			}
 		}
@@ -400,7 +663,7 @@ func parsePrereqStepsFromLua(val lua.LValue) []PrereqStep {
 	return nil
 }
 
-func parseCommandPhaseFromLua(val lua.LValue) CommandPhase {
+func parseCommandPhaseFromLua(val lua.LValue) (CommandPhase, error) {
 	var phase CommandPhase
 
 	if t, ok := val.(*lua.LTable); ok {
@@ -416,16 +679,9 @@ func parseCommandPhaseFromLua(val lua.LValue) CommandPhase {
 			p := stepsTbl.RawGetInt(i)
 			if stepTbl, ok := p.(*lua.LTable); ok {
 				gs := GameStep{
-					Name: stepTbl.RawGetString("name").String(),
-				}
-
-				f := stepTbl.RawGetString("value")
-				if str, ok := f.(lua.LString); ok {
-					gs.Value = string(str)
-				} else if fn, ok := f.(*lua.LFunction); ok {
-					gs.Value = fn
-				} else if bl, ok := f.(lua.LBool); ok {
-					gs.Value = bool(bl)
+					Name:   stepTbl.RawGetString("name").String(),
+					Value:  parseFormulaFromLua(stepTbl.RawGetString("value")),
+					Secret: lua.LVAsBool(stepTbl.RawGetString("secret")),
 				}
 
 				phase.Steps = append(phase.Steps, gs)
@@ -438,27 +694,58 @@ func parseCommandPhaseFromLua(val lua.LValue) CommandPhase {
 				for i := 1; i <= hooksTbl.Len(); i++ {
 					p := hooksTbl.RawGetInt(i)
 					if hookTbl, ok := p.(*lua.LTable); ok {
-						hd := HookDef{
-							Name: hookTbl.RawGetString("name").String(),
-							Type: hookTbl.RawGetString("type").String(),
-						}
-
-						f := hookTbl.RawGetString("value")
-						if str, ok := f.(lua.LString); ok {
-							hd.Value = string(str)
-						} else if fn, ok := f.(*lua.LFunction); ok {
-							hd.Value = fn
-						} else if bl, ok := f.(lua.LBool); ok {
-							hd.Value = bool(bl)
+						hd, err := parseHookDefFromLua(hookTbl)
+						if err != nil {
+							return phase, err
 						}
-
 						phase.Hooks = append(phase.Hooks, hd)
 					}
 				}
 			}
 		}
 	}
-	return phase
+	return phase, nil
+}
+
+// parseHookDefFromLua reads a hook table. `repeats` accepts "once", "always" or a number
+// of firings; a hook with only `stop_when` repeats until the predicate holds.
+func parseHookDefFromLua(t *lua.LTable) (HookDef, error) {
+	hd := HookDef{
+		Name:     t.RawGetString("name").String(),
+		Type:     t.RawGetString("type").String(),
+		Value:    parseFormulaFromLua(t.RawGetString("value")),
+		StopWhen: parseFormulaFromLua(t.RawGetString("stop_when")),
+	}
+
+	switch r := t.RawGetString("repeats").(type) {
+	case lua.LNumber:
+		hd.Repeats = HookRepeatCount
+		hd.Count = int(r)
+	case lua.LString:
+		if r != HookRepeatOnce && r != HookRepeatAlways {
+			return hd, fmt.Errorf("hook %s: repeats must be %q, %q or a number of firings, not %q", hd.Name, HookRepeatOnce, HookRepeatAlways, string(r))
+		}
+		hd.Repeats = string(r)
+	default:
+		if hd.StopWhen != nil {
+			hd.Repeats = HookRepeatAlways
+		}
+	}
+	return hd, nil
+}
+
+// parseFormulaFromLua extracts a formula (string, closure or boolean literal) from a Lua value.
+// Any other value yields nil.
+func parseFormulaFromLua(f lua.LValue) any {
+	switch v := f.(type) {
+	case lua.LString:
+		return string(v)
+	case *lua.LFunction:
+		return v
+	case lua.LBool:
+		return bool(v)
+	}
+	return nil
 }
 
 func parseRestrictionsFromLua(t *lua.LTable) Restrictions {
@@ -487,6 +774,51 @@ func parseRestrictionsFromLua(t *lua.LTable) Restrictions {
 	return r
 }
 
+func parseVisibilityFromLua(t *lua.LTable) Visibility {
+	var v Visibility
+
+	if res, ok := t.RawGetString("resources").(*lua.LTable); ok {
+		v.Resources = make(map[string][]ResourceBand)
+		res.ForEach(func(k, val lua.LValue) {
+			bandsTbl, ok := val.(*lua.LTable)
+			if !ok {
+				return
+			}
+			var bands []ResourceBand
+			for i := 1; i <= bandsTbl.Len(); i++ {
+				bandTbl, ok := bandsTbl.RawGetInt(i).(*lua.LTable)
+				if !ok {
+					continue
+				}
+				band := ResourceBand{Label: lua.LVAsString(bandTbl.RawGetString("label"))}
+				if n, ok := bandTbl.RawGetString("at_most").(lua.LNumber); ok {
+					atMost := float64(n)
+					band.AtMost = &atMost
+				}
+				bands = append(bands, band)
+			}
+			v.Resources[k.String()] = bands
+		})
+	}
+	v.HiddenConditions = luaStringList(t.RawGetString("hidden_conditions"))
+	v.PublicMetadata = luaStringList(t.RawGetString("public_metadata"))
+
+	return v
+}
+
+// luaStringList converts a Lua array of strings into a slice; other values yield nil.
+func luaStringList(val lua.LValue) []string {
+	t, ok := val.(*lua.LTable)
+	if !ok {
+		return nil
+	}
+	var list []string
+	for i := 1; i <= t.Len(); i++ {
+		list = append(list, t.RawGetInt(i).String())
+	}
+	return list
+}
+
 // goValueToLua converts native Go values to Lua values.
 func goValueToLua(L *lua.LState, val any) lua.LValue {
 	if val == nil {
@@ -543,6 +875,8 @@ func goValueToLua(L *lua.LState, val any) lua.LValue {
 			}
 			return 1
 		})
+	case lua.LGFunction:
+		return L.NewFunction(v)
 	// Handling *lua.LFunction for nested function support if needed
 	case lua.LValue:
 		return v
@@ -619,6 +953,8 @@ func BuildContext(state *GameState, actor *Entity, target *Entity, params map[st
 		ctx["is_"+name+"_active"] = func() any { return active }
 	}
 
+	maps.Copy(ctx, queryFunctions(state))
+
 	ctx["current_actor"] = func() any {
 		for _, loop := range state.Loops {
 			if loop.Active && len(loop.Actors) > 0 {
@@ -651,15 +987,53 @@ func entityToMap(e *Entity) map[string]any {
 		"proficiencies": e.Proficiencies,
 		"statuses":      e.Statuses,
 		"inventory":     e.Inventory,
+		"hooks":         hooksToMap(e.Hooks),
+		"actions":       actionsToMap(e.Actions),
+	}
+}
+
+// actionsToMap exposes the actions of an entity keyed by action ID.
+func actionsToMap(actions map[string]Action) map[string]any {
+	m := make(map[string]any, len(actions))
+	for id, a := range actions {
+		damage := make([]any, 0, len(a.Damage))
+		for _, d := range a.Damage {
+			damage = append(damage, map[string]any{"dice": d.Dice, "type": d.Type})
+		}
+		m[id] = map[string]any{
+			"name":         a.Name,
+			"desc":         a.Desc,
+			"attack_bonus": a.AttackBonus,
+			"damage":       damage,
+			"dc":           a.DC,
+			"dc_ability":   a.DCAbility,
+			"dc_success":   a.DCSuccess,
+		}
+	}
+	return m
+}
+
+// hooksToMap exposes the active hooks of an entity (type, repeat policy and remaining firings).
+func hooksToMap(hooks map[string]Hook) map[string]any {
+	m := make(map[string]any, len(hooks))
+	for name, h := range hooks {
+		m[name] = map[string]any{
+			"type":      h.Type,
+			"repeats":   h.Repeats,
+			"remaining": h.Remaining,
+		}
 	}
+	return m
 }
 
+// defaultRoll rolls a dice expression of the form NdS with an optional flat modifier (e.g., "2d6+2").
 func defaultRoll(dice string) int {
-	var count, sides int
-	if _, err := fmt.Sscanf(dice, "%dd%d", &count, &sides); err != nil || sides <= 0 {
+	var count, sides, mod int
+	n, _ := fmt.Sscanf(strings.ReplaceAll(dice, " ", ""), "%dd%d%d", &count, &sides, &mod)
+	if n < 2 || sides <= 0 {
 		return 0
 	}
-	total := 0
+	total := mod
 	for i := 0; i < count; i++ {
 		total += rand.Intn(sides) + 1
 	}
@@ -1,7 +1,10 @@
 package engine
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -74,6 +77,87 @@ func TestLuaEvaluator_EvalError(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestLuaEvaluator_Limits(t *testing.T) {
+	eval, err := NewLuaEvaluatorWithLimits(func(dice string) int { return 10 }, Limits{
+		Timeout:   50 * time.Millisecond,
+		CallDepth: 100,
+		StackSize: 64 * 1024,
+	})
+	require.NoError(t, err)
+	defer eval.Close()
+
+	require.NoError(t, eval.L.DoString(`
+function spin() while true do end end
+function deep(n) return 1 + deep(n + 1) end
+`))
+
+	// An infinite loop in a string formula hits the timeout
+	_, err = eval.Eval("(function() while true do end end)()", nil)
+	require.ErrorIs(t, err, ErrLimitExceeded)
+	assert.ErrorContains(t, err, "formula ran longer than 50ms")
+
+	// So does a closure
+	_, err = eval.Eval(eval.L.GetGlobal("spin"), nil)
+	require.ErrorIs(t, err, ErrLimitExceeded)
+
+	// Runaway recursion overflows the call stack
+	_, err = eval.Eval("deep(1)", nil)
+	require.ErrorIs(t, err, ErrLimitExceeded)
+	assert.ErrorContains(t, err, "recursed too deeply")
+
+	// Ordinary errors are not limit errors, and the evaluator keeps working
+	_, err = eval.Eval("nil + 1", nil)
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, ErrLimitExceeded)
+	result, err := eval.Eval("2 + 3", nil)
+	require.NoError(t, err)
+	assert.Equal(t, 5, result)
+}
+
+func TestLuaEvaluator_MemoryLimit(t *testing.T) {
+	eval, err := NewLuaEvaluatorWithLimits(func(dice string) int { return 10 }, Limits{
+		Timeout: 10 * time.Second,
+		Memory:  64 << 20,
+	})
+	require.NoError(t, err)
+	defer eval.Close()
+
+	// Filling a table grows the heap past the limit long before the timeout
+	_, err = eval.Eval(`(function() local t = {} while true do t[#t+1] = string.rep("x", 1e6) end end)()`, nil)
+	require.ErrorIs(t, err, ErrLimitExceeded)
+	assert.ErrorContains(t, err, "formula used more than 67108864 bytes of memory")
+
+	// A single string larger than the limit is refused before it is allocated
+	_, err = eval.Eval(`string.rep("x", 1e9)`, nil)
+	require.ErrorIs(t, err, ErrLimitExceeded)
+
+	result, err := eval.Eval(`string.rep("ab", 3)`, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "ababab", result)
+}
+
+func TestLoadManifestLua_IsBoundedByTheLimits(t *testing.T) {
+	dir := t.TempDir()
+	writeLuaFiles(t, dir, map[string]string{
+		"manifest.lua": `commands = {}`,
+		"spin.lua":     `while true do end`,
+	})
+	limits := Limits{Timeout: 50 * time.Millisecond}
+
+	eval, err := NewLuaEvaluatorWithLimits(mockRoll, limits)
+	require.NoError(t, err)
+	defer eval.Close()
+	_, err = eval.LoadManifestLua(filepath.Join(dir, "spin.lua"))
+	require.ErrorIs(t, err, ErrLimitExceeded)
+
+	eval, err = NewLuaEvaluatorWithLimits(mockRoll, limits)
+	require.NoError(t, err)
+	defer eval.Close()
+	_, err = eval.LoadManifestLua(filepath.Join(dir, "manifest.lua"), filepath.Join(dir, "spin.lua"))
+	require.ErrorIs(t, err, ErrLimitExceeded)
+	assert.ErrorContains(t, err, "failed to load overlay spin.lua")
+}
+
 func TestLuaEvaluator_EvalLiteralTypes(t *testing.T) {
 	eval, err := NewLuaEvaluator(func(dice string) int { return 10 })
 	require.NoError(t, err)
@@ -205,3 +289,57 @@ func TestLuaHelpers_AllHelpers(t *testing.T) {
 		})
 	}
 }
+
+func TestLoadManifestLua_HookRepeatPolicies(t *testing.T) {
+	eval, err := NewLuaEvaluator(func(dice string) int { return 10 })
+	require.NoError(t, err)
+	defer eval.Close()
+
+	path := filepath.Join(t.TempDir(), "manifest.lua")
+	require.NoError(t, os.WriteFile(path, []byte(`
+commands = {
+    regenerate = {
+        name = "regenerate",
+        actor = {
+            hooks = {
+                { name = "once", type = "next_turn", value = "nil" },
+                { name = "counted", type = "next_turn", value = "nil", repeats = 3 },
+                { name = "forever", type = "next_turn", value = "nil", repeats = "always" },
+                { name = "until", type = "next_turn", value = "nil", stop_when = function() return true end },
+            },
+        },
+    },
+}
+`), 0644))
+
+	m, err := eval.LoadManifestLua(path)
+	require.NoError(t, err)
+	hooks := m.Commands["regenerate"].Actor.Hooks
+	require.Len(t, hooks, 4)
+
+	assert.Equal(t, "", hooks[0].Repeats)
+	assert.Equal(t, HookRepeatCount, hooks[1].Repeats)
+	assert.Equal(t, 3, hooks[1].Count)
+	assert.Equal(t, HookRepeatAlways, hooks[2].Repeats)
+	assert.Equal(t, HookRepeatAlways, hooks[3].Repeats)
+	assert.NotNil(t, hooks[3].StopWhen)
+}
+
+func TestLoadManifestLua_RejectsUnknownHookRepeats(t *testing.T) {
+	eval, err := NewLuaEvaluator(func(dice string) int { return 10 })
+	require.NoError(t, err)
+	defer eval.Close()
+
+	path := filepath.Join(t.TempDir(), "manifest.lua")
+	require.NoError(t, os.WriteFile(path, []byte(`
+commands = {
+    regenerate = {
+        name = "regenerate",
+        actor = { hooks = { { name = "heal", type = "next_turn", value = "nil", repeats = "allways" } } },
+    },
+}
+`), 0644))
+
+	_, err = eval.LoadManifestLua(path)
+	assert.EqualError(t, err, `commands.regenerate: hook heal: repeats must be "once", "always" or a number of firings, not "allways"`)
+}
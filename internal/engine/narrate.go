@@ -0,0 +1,96 @@
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+
+	lua "github.com/yuin/gopher-lua"
+)
+
+// NarratedEvent is an event together with the text a manifest formatter wrote for it. It is
+// logged and applied as the event it wraps; only its message differs.
+type NarratedEvent struct {
+	Event
+	Text string
+}
+
+func (e *NarratedEvent) Message() string { return e.Text }
+
+// Unwrap returns the narrated event.
+func (e *NarratedEvent) Unwrap() Event { return e.Event }
+
+func (e *NarratedEvent) MarshalJSON() ([]byte, error) { return json.Marshal(e.Event) }
+
+// Formatter returns the formatter for events of a type emitted by a command: the one in the
+// command's format table, else the one in the manifest's formatters table.
+func (m *Manifest) Formatter(cmdName, eventType string) (any, bool) {
+	if f, ok := m.Commands[cmdName].Format[eventType]; ok {
+		return f, true
+	}
+	f, ok := m.Formatters[eventType]
+	return f, ok
+}
+
+// DisplayNames maps the IDs of the state's entities to their names, or to the ID for entities
+// without one.
+func DisplayNames(state *GameState) map[string]string {
+	names := make(map[string]string, len(state.Entities))
+	for id, ent := range state.Entities {
+		names[id] = ent.Name
+		if ent.Name == "" {
+			names[id] = id
+		}
+	}
+	return names
+}
+
+// NarrateEvent calls a formatter with the event, as a table of its logged fields plus its type
+// and built-in message, and the display names of the entities. The event is returned
+// narrated by the string the formatter returns, or unchanged when it returns anything else.
+func NarrateEvent(evt Event, formatter any, names map[string]string, eval *LuaEvaluator) Event {
+	fields, err := eventFields(evt, eval.catalog)
+	if err != nil {
+		return evt
+	}
+	result, err := eval.Eval(formatter, nil, fields, names)
+	if err != nil {
+		return &NarratedEvent{Event: evt, Text: fmt.Sprintf("%s (format failed: %v)", MessageIn(evt, eval.catalog), luaErrorMessage(err))}
+	}
+	text, ok := result.(string)
+	if !ok {
+		return evt
+	}
+	return &NarratedEvent{Event: evt, Text: text}
+}
+
+func eventFields(evt Event, c *Catalog) (map[string]any, error) {
+	data, err := json.Marshal(evt)
+	if err != nil {
+		return nil, err
+	}
+	fields := make(map[string]any)
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	fields["type"] = evt.Type()
+	fields["message"] = MessageIn(evt, c)
+	return fields, nil
+}
+
+// parseFormattersFromLua reads a table of formatter functions by event type, such as the
+// manifest's formatters table or a command's format table.
+func parseFormattersFromLua(where string, t *lua.LTable) (map[string]any, error) {
+	formatters := make(map[string]any)
+	var err error
+	t.ForEach(func(k, v lua.LValue) {
+		fn, ok := v.(*lua.LFunction)
+		if !ok {
+			if err == nil {
+				err = fmt.Errorf("%s.%s must be a function(event, names)", where, k.String())
+			}
+			return
+		}
+		formatters[k.String()] = fn
+	})
+	return formatters, err
+}
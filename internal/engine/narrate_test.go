@@ -0,0 +1,74 @@
+package engine
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadManifestLua_ReadsFormatters(t *testing.T) {
+	dir := t.TempDir()
+	writeLuaFiles(t, dir, map[string]string{"manifest.lua": `
+formatters = {
+    ConditionEvent = function(e, names)
+        if e.add then return names[e.actor_id] .. " is " .. e.condition end
+    end,
+    AttributeChangedEvent = function(e, names) return e.nope.field end,
+}
+commands = {
+    grapple = {
+        name = "grapple",
+        format = { ConditionEvent = function(e, names) return names[e.actor_id] .. " is held fast" end },
+    },
+}
+`})
+	eval, err := NewLuaEvaluator(mockRoll)
+	require.NoError(t, err)
+	defer eval.Close()
+
+	m, err := eval.LoadManifestLua(filepath.Join(dir, "manifest.lua"))
+	require.NoError(t, err)
+	names := DisplayNames(testState())
+
+	added := &ConditionEvent{ActorID: "goblin", Condition: "grappled", Add: true}
+	f, ok := m.Formatter("grapple", added.Type())
+	require.True(t, ok)
+	assert.Equal(t, "Goblin is held fast", NarrateEvent(added, f, names, eval).Message())
+
+	f, ok = m.Formatter("shove", added.Type())
+	require.True(t, ok)
+	narrated := NarrateEvent(added, f, names, eval)
+	assert.Equal(t, "Goblin is grappled", narrated.Message())
+	assert.Equal(t, "ConditionEvent", narrated.Type())
+	assert.Same(t, added, Unwrap(narrated))
+	data, err := json.Marshal(narrated)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"actor_id":"goblin","condition":"grappled","add":true}`, string(data))
+
+	// A formatter returning nothing keeps the built-in message
+	removed := &ConditionEvent{ActorID: "goblin", Condition: "grappled"}
+	assert.Same(t, removed, NarrateEvent(removed, f, names, eval))
+
+	changed := &AttributeChangedEvent{ActorID: "goblin", Section: "spent", Key: "hp", Value: 3}
+	f, _ = m.Formatter("", changed.Type())
+	assert.Contains(t, NarrateEvent(changed, f, names, eval).Message(), "goblin.spent.hp changed (format failed:")
+
+	_, ok = m.Formatter("", "RoundStartedEvent")
+	assert.False(t, ok)
+}
+
+func TestLoadManifestLua_RejectsFormattersThatAreNotFunctions(t *testing.T) {
+	dir := t.TempDir()
+	writeLuaFiles(t, dir, map[string]string{"manifest.lua": `
+commands = { dash = { name = "dash", format = { TurnEndedEvent = "dashes" } } }
+`})
+	eval, err := NewLuaEvaluator(mockRoll)
+	require.NoError(t, err)
+	defer eval.Close()
+
+	_, err = eval.LoadManifestLua(filepath.Join(dir, "manifest.lua"))
+	assert.EqualError(t, err, "commands.dash.format.TurnEndedEvent must be a function(event, names)")
+}
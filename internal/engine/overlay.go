@@ -0,0 +1,77 @@
+package engine
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	lua "github.com/yuin/gopher-lua"
+)
+
+// layerName is the name under which help shows the commands an overlay file adds or changes:
+// the file name without its extension.
+func layerName(path string) string {
+	return strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+}
+
+// loadOverlays runs the overlay files, in order, in the state the manifest was loaded into.
+// Overlays are plain Lua that change the manifest's globals: they assign commands to add or
+// replace them, set fields inside a command to patch it, and redefine helper functions or
+// restrictions. It returns the layer that last added or changed each command.
+func (ev *LuaEvaluator) loadOverlays(overlays []string) (map[string]string, error) {
+	layers := make(map[string]string)
+	for _, path := range overlays {
+		before := commandSignatures(ev.L)
+		if err := ev.doFile(path); err != nil {
+			return nil, fmt.Errorf("failed to load overlay %s: %w", filepath.Base(path), err)
+		}
+		for name, sig := range commandSignatures(ev.L) {
+			if before[name] != sig {
+				layers[name] = layerName(path)
+			}
+		}
+	}
+	return layers, nil
+}
+
+// commandSignatures describes every entry of the commands table, so that comparing them
+// before and after an overlay tells which commands it touched.
+func commandSignatures(L *lua.LState) map[string]string {
+	sigs := make(map[string]string)
+	if cmds, ok := L.GetGlobal("commands").(*lua.LTable); ok {
+		cmds.ForEach(func(k, v lua.LValue) {
+			var b strings.Builder
+			writeSignature(&b, v, nil)
+			sigs[k.String()] = b.String()
+		})
+	}
+	return sigs
+}
+
+// writeSignature renders a value with its tables expanded, in key order, and functions by
+// identity.
+func writeSignature(b *strings.Builder, v lua.LValue, seen []*lua.LTable) {
+	switch t := v.(type) {
+	case *lua.LTable:
+		if slices.Contains(seen, t) {
+			b.WriteString("<cycle>")
+			return
+		}
+		seen = append(seen, t)
+		var keys []lua.LValue
+		t.ForEach(func(k, _ lua.LValue) { keys = append(keys, k) })
+		slices.SortFunc(keys, func(a, b lua.LValue) int { return strings.Compare(a.String(), b.String()) })
+		b.WriteString("{")
+		for _, k := range keys {
+			fmt.Fprintf(b, "%s=", k.String())
+			writeSignature(b, t.RawGet(k), seen)
+			b.WriteString(",")
+		}
+		b.WriteString("}")
+	case *lua.LFunction:
+		fmt.Fprintf(b, "function:%p", t)
+	default:
+		fmt.Fprintf(b, "%s:%s", v.Type(), v.String())
+	}
+}
@@ -0,0 +1,82 @@
+package engine
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const overlayBase = `
+function damage_bonus() return 2 end
+
+commands = {
+    strike = {
+        name = "strike",
+        help = "Hit something.",
+        game = { steps = { { name = "damage", value = function() return roll("1d6") + damage_bonus() end } } },
+    },
+    dash = { name = "dash", help = "Move faster.", hint = "You dash." },
+    rest = { name = "rest", help = "Take a rest." },
+}
+
+restrictions = { gm_commands = { "rest" } }
+`
+
+func TestLoadManifestLua_AppliesOverlaysInOrder(t *testing.T) {
+	dir := t.TempDir()
+	writeLuaFiles(t, dir, map[string]string{
+		"manifest.lua": overlayBase,
+		"overlays/a_house_rules.lua": `
+function damage_bonus() return 5 end
+commands.dash.hint = "You dash twice as far."
+commands.rest = nil
+commands.shove = { name = "shove", help = "Push someone." }
+restrictions.gm_commands = {}
+`,
+		"overlays/b_more.lua": `commands.shove.help = "Push someone hard."`,
+	})
+	eval, err := NewLuaEvaluator(mockRoll)
+	require.NoError(t, err)
+	defer eval.Close()
+
+	m, err := eval.LoadManifestLua(filepath.Join(dir, "manifest.lua"),
+		filepath.Join(dir, "overlays", "a_house_rules.lua"), filepath.Join(dir, "overlays", "b_more.lua"))
+	require.NoError(t, err)
+
+	assert.Empty(t, m.Commands["strike"].Layer, "redefining a helper does not change the command table")
+	assert.Equal(t, "a_house_rules", m.Commands["dash"].Layer)
+	assert.Equal(t, "You dash twice as far.", m.Commands["dash"].Hint)
+	assert.Equal(t, "b_more", m.Commands["shove"].Layer)
+	assert.Equal(t, "Push someone hard.", m.Commands["shove"].Help)
+	assert.NotContains(t, m.Commands, "rest")
+	assert.Empty(t, m.Restrictions.GMCommands)
+
+	// Closures of the base manifest see the overlay's helper
+	damage, err := eval.Eval(m.Commands["strike"].Game.Steps[0].Value, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 15, damage)
+
+	events, err := executeHelp(map[string]any{"command": "shove"}, m)
+	require.NoError(t, err)
+	assert.Contains(t, events[0].Message(), "Defined in overlay: b_more")
+	events, err = executeHelp(map[string]any{}, m)
+	require.NoError(t, err)
+	assert.Contains(t, events[0].Message(), "**dash** — Move faster. [a_house_rules]")
+	assert.NotContains(t, events[0].Message(), "Hit something. [")
+}
+
+func TestLoadManifestLua_ReportsOverlayErrors(t *testing.T) {
+	dir := t.TempDir()
+	writeLuaFiles(t, dir, map[string]string{
+		"manifest.lua":        overlayBase,
+		"overlays/broken.lua": `commands.missing.hint = "x"`,
+	})
+	eval, err := NewLuaEvaluator(mockRoll)
+	require.NoError(t, err)
+	defer eval.Close()
+
+	_, err = eval.LoadManifestLua(filepath.Join(dir, "manifest.lua"), filepath.Join(dir, "overlays", "broken.lua"))
+	assert.ErrorContains(t, err, "failed to load overlay broken.lua")
+}
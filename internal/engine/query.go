@@ -0,0 +1,110 @@
+package engine
+
+import (
+	"maps"
+	"slices"
+	"strings"
+
+	lua "github.com/yuin/gopher-lua"
+)
+
+// queryFunctions returns the read-only world query functions injected into every evaluation.
+// Each call builds fresh Lua tables from the live state, so formulas can inspect any entity
+// but can only change the game through event helpers.
+//
+//	entity(id)                 -> entity table, or nil
+//	entities([filter], [pred]) -> entity tables sorted by ID
+//	loop_actors(name)          -> actor IDs of a loop in turn order
+//	has_condition(id, c)       -> whether the entity has condition c
+//
+// An entities filter table may set type, condition, class ("key=value" or just "key") and loop;
+// pred is a function called with each entity table that returns whether to keep it.
+func queryFunctions(state *GameState) map[string]any {
+	return map[string]any{
+		"entity": lua.LGFunction(func(L *lua.LState) int {
+			ent := state.Entities[L.CheckString(1)]
+			if ent == nil {
+				L.Push(lua.LNil)
+				return 1
+			}
+			L.Push(goValueToLua(L, entityToMap(ent)))
+			return 1
+		}),
+
+		"entities": lua.LGFunction(func(L *lua.LState) int {
+			filter, _ := L.Get(1).(*lua.LTable)
+			pred, _ := L.Get(2).(*lua.LFunction)
+			if fn, ok := L.Get(1).(*lua.LFunction); ok {
+				pred = fn
+			}
+
+			result := L.NewTable()
+			for _, id := range slices.Sorted(maps.Keys(state.Entities)) {
+				ent := state.Entities[id]
+				if filter != nil && !matchesFilter(state, ent, filter) {
+					continue
+				}
+				tbl := goValueToLua(L, entityToMap(ent))
+				if pred != nil {
+					L.Push(pred)
+					L.Push(tbl)
+					L.Call(1, 1)
+					keep := lua.LVAsBool(L.Get(-1))
+					L.Pop(1)
+					if !keep {
+						continue
+					}
+				}
+				result.Append(tbl)
+			}
+			L.Push(result)
+			return 1
+		}),
+
+		"loop_actors": lua.LGFunction(func(L *lua.LState) int {
+			var actors []string
+			if loop, ok := state.Loops[L.CheckString(1)]; ok {
+				actors = sortedActors(loop)
+			}
+			L.Push(goValueToLua(L, actors))
+			return 1
+		}),
+
+		"has_condition": lua.LGFunction(func(L *lua.LState) int {
+			ent := state.Entities[L.CheckString(1)]
+			L.Push(lua.LBool(ent != nil && hasCondition(ent, L.CheckString(2))))
+			return 1
+		}),
+	}
+}
+
+// matchesFilter reports whether an entity satisfies every field of an entities() filter table.
+func matchesFilter(state *GameState, ent *Entity, filter *lua.LTable) bool {
+	if t := lua.LVAsString(filter.RawGetString("type")); t != "" {
+		if !slices.ContainsFunc(ent.Types, func(s string) bool { return strings.EqualFold(s, t) }) {
+			return false
+		}
+	}
+	if c := lua.LVAsString(filter.RawGetString("condition")); c != "" && !hasCondition(ent, c) {
+		return false
+	}
+	if class := lua.LVAsString(filter.RawGetString("class")); class != "" {
+		key, value, hasValue := strings.Cut(class, "=")
+		got, ok := ent.Classes[key]
+		if !ok || (hasValue && !strings.EqualFold(got, value)) {
+			return false
+		}
+	}
+	if name := lua.LVAsString(filter.RawGetString("loop")); name != "" {
+		loop, ok := state.Loops[name]
+		if !ok || !slices.Contains(loop.Actors, ent.ID) {
+			return false
+		}
+	}
+	return true
+}
+
+// hasCondition reports whether the entity has the condition, ignoring case.
+func hasCondition(ent *Entity, condition string) bool {
+	return slices.ContainsFunc(ent.Conditions, func(c string) bool { return strings.EqualFold(c, condition) })
+}
@@ -0,0 +1,88 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func queryTestState() *GameState {
+	state := NewGameState()
+	for _, e := range []struct {
+		id, kind  string
+		hp, spent int
+		conds     []string
+	}{
+		{"elara", "character", 12, 8, nil},
+		{"brom", "character", 20, 2, []string{"Grappled"}},
+		{"goblin_a", "monster", 7, 0, []string{"prone"}},
+		{"goblin_b", "monster", 7, 0, nil},
+	} {
+		ent := NewEntity(e.id, e.id)
+		ent.Types = []string{e.kind}
+		ent.Resources["hp"] = e.hp
+		ent.Spent["hp"] = e.spent
+		ent.Conditions = append(ent.Conditions, e.conds...)
+		state.Entities[e.id] = ent
+	}
+	state.Entities["goblin_a"].Classes["size"] = "small"
+	state.Loops["encounter"] = &Loop{
+		Active: true,
+		Actors: []string{"elara", "goblin_a", "brom"},
+		Order:  map[string]int{"elara": 12, "goblin_a": 18, "brom": 5},
+	}
+	return state
+}
+
+func TestQueryFunctions(t *testing.T) {
+	eval, err := NewLuaEvaluator(mockRoll)
+	require.NoError(t, err)
+	defer eval.Close()
+
+	state := queryTestState()
+	ctx := BuildContext(state, state.Entities["elara"], nil, nil, nil, nil, nil)
+
+	tests := []struct {
+		name    string
+		formula string
+		want    any
+	}{
+		{"entity", "entity('brom').resources.hp", 20},
+		{"missing entity", "entity('dragon') == nil", true},
+		{"all entities", "#entities()", 4},
+		{"by type", "entities({ type = 'monster' })[2].id", "goblin_b"},
+		{"by condition", "entities({ condition = 'grappled' })[1].id", "brom"},
+		{"by class", "#entities({ class = 'size=small' })", 1},
+		{"by loop", "#entities({ loop = 'encounter', type = 'monster' })", 1},
+		{"predicate", "entities(function(e) return e.resources.hp - (e.spent.hp or 0) < e.resources.hp / 2 end)[1].id", "elara"},
+		{"filter and predicate", "#entities({ type = 'character' }, function(e) return e.id ~= actor.id end)", 1},
+		{"loop actors in turn order", "table.concat(loop_actors('encounter'), ',')", "goblin_a,elara,brom"},
+		{"unknown loop", "#loop_actors('chase')", 0},
+		{"has condition", "has_condition('brom', 'grappled')", true},
+		{"has no condition", "has_condition('elara', 'grappled')", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := eval.Eval(tt.formula, ctx)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, result)
+		})
+	}
+}
+
+func TestQueryFunctions_ReadOnly(t *testing.T) {
+	eval, err := NewLuaEvaluator(mockRoll)
+	require.NoError(t, err)
+	defer eval.Close()
+
+	state := queryTestState()
+	ctx := BuildContext(state, nil, nil, nil, nil, nil, nil)
+
+	_, err = eval.Eval("(function() local g = entity('goblin_a'); g.resources.hp = 0; g.conditions[1] = nil; entities()[1].spent.hp = 99 end)()", ctx)
+	require.NoError(t, err)
+
+	assert.Equal(t, 7, state.Entities["goblin_a"].Resources["hp"])
+	assert.Equal(t, []string{"prone"}, state.Entities["goblin_a"].Conditions)
+	assert.Equal(t, 2, state.Entities["brom"].Spent["hp"])
+}
@@ -0,0 +1,175 @@
+package engine
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+
+	lua "github.com/yuin/gopher-lua"
+)
+
+// helperTags are the _event tags dispatchTaggedResult turns into built-in events. Declared
+// event types cannot take these names.
+var helperTags = []string{
+	"loop", "loop_order", "loop_value", "add_actor", "ask", "condition", "spend",
+	"set_attr", "contest", "check", "hint", "metadata", "next_turn",
+}
+
+// eventNamePattern keeps declared event types apart from the built-in event types of the log,
+// which are CamelCase.
+var eventNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// IsDeclaredEventName reports whether a log entry's type can be the name of a declared event.
+func IsDeclaredEventName(name string) bool {
+	return eventNamePattern.MatchString(name)
+}
+
+// DeclaredEvent is an event of a type declared in the manifest's events table. It is logged
+// under its own type name and applied through the manifest's reducer, also when replayed.
+type DeclaredEvent struct {
+	EventType string         `json:"-"` // the log entry's type
+	ActorID   string         `json:"actor_id"`
+	TargetID  string         `json:"target_id,omitempty"`
+	Payload   map[string]any `json:"payload"`
+	Text      string         `json:"message,omitempty"` // formatted when the event was created
+}
+
+func (e *DeclaredEvent) Type() string { return e.EventType }
+func (e *DeclaredEvent) Apply(state *GameState) error {
+	b, ok := state.events[e.EventType]
+	if !ok {
+		return fmt.Errorf("event type %s is not declared in the manifest", e.EventType)
+	}
+	return b.apply(state, e)
+}
+func (e *DeclaredEvent) Message() string { return e.MessageIn(nil) }
+func (e *DeclaredEvent) MessageIn(c *Catalog) string {
+	if e.Text != "" {
+		return e.Text
+	}
+	return c.Text("event.declared", e.EventType)
+}
+
+// boundEvent is a declared event type together with the evaluator that owns its closures.
+type boundEvent struct {
+	def  EventDef
+	eval *LuaEvaluator
+}
+
+// BindEvents makes the event types declared in the manifest available to the state: emitting
+// one of them creates a DeclaredEvent, which is applied through the manifest's reducer. It must
+// be called before replaying a log that holds declared events, and again after a reload.
+// Only the declared types of the last manifest bound can be logged; see Declares. The state
+// also keeps the manifest's catalog, for the messages it stores.
+func BindEvents(state *GameState, m *Manifest, eval *LuaEvaluator) {
+	state.catalog = m.Catalog
+	state.events = make(map[string]boundEvent, len(m.Events))
+	for name, def := range m.Events {
+		state.events[name] = boundEvent{def: def, eval: eval}
+	}
+}
+
+// Declares reports whether the state's manifest declares an event type, so that its events can
+// be logged; see BindEvents.
+func (s *GameState) Declares(typeName string) bool {
+	_, ok := s.events[typeName]
+	return ok
+}
+
+// newEvent creates an event of this type, formatting its message from the payload.
+func (b boundEvent) newEvent(actorID, targetID string, payload map[string]any) *DeclaredEvent {
+	e := &DeclaredEvent{EventType: b.def.Name, ActorID: actorID, TargetID: targetID, Payload: payload}
+	switch msg := b.def.Message.(type) {
+	case string:
+		e.Text = msg
+	case *lua.LFunction:
+		result, err := b.eval.Eval(msg, nil, payload)
+		if err != nil {
+			e.Text = fmt.Sprintf("%s event (message failed: %v)", b.def.Name, luaErrorMessage(err))
+		} else if text, ok := result.(string); ok {
+			e.Text = text
+		}
+	}
+	return e
+}
+
+// apply runs the reducer with a view of the state and the payload, then applies the events of
+// the helper results it returns, in order. The view is a copy: changing it has no effect.
+// Reducers run again on every replay, so they cannot roll dice.
+func (b boundEvent) apply(state *GameState, e *DeclaredEvent) error {
+	ctx := BuildContext(state, state.Entities[e.ActorID], state.Entities[e.TargetID], nil, nil, nil, nil)
+	b.eval.reducing = true
+	result, err := b.eval.Eval(b.def.Apply, ctx, stateView(state), e.Payload)
+	b.eval.reducing = false
+	if err != nil {
+		return fmt.Errorf("event %s: reducer failed: %w", e.EventType, err)
+	}
+
+	effects, _ := dispatchTaggedResult(result, e.ActorID, e.TargetID, e.EventType, state)
+	for _, effect := range effects {
+		if nested, ok := effect.(*DeclaredEvent); ok {
+			return fmt.Errorf("event %s: a reducer cannot produce the declared event %s", e.EventType, nested.EventType)
+		}
+		if err := effect.Apply(state); err != nil {
+			return fmt.Errorf("event %s: %w", e.EventType, err)
+		}
+	}
+	return nil
+}
+
+// stateView is the state as reducers see it: entities by ID, loops by name and metadata.
+func stateView(state *GameState) map[string]any {
+	entities := make(map[string]any, len(state.Entities))
+	for id, ent := range state.Entities {
+		entities[id] = entityToMap(ent)
+	}
+	loops := make(map[string]any, len(state.Loops))
+	for name, loop := range state.Loops {
+		loops[name] = map[string]any{
+			"active": loop.Active,
+			"actors": sortedActors(loop),
+			"round":  loop.Round,
+			"turn":   loop.Turn,
+		}
+	}
+	return map[string]any{"entities": entities, "loops": loops, "metadata": state.Metadata}
+}
+
+// parseEventsFromLua reads the manifest's events table.
+func parseEventsFromLua(t *lua.LTable) (map[string]EventDef, error) {
+	events := make(map[string]EventDef)
+	var err error
+	t.ForEach(func(k, v lua.LValue) {
+		if err != nil {
+			return
+		}
+		name := k.String()
+		tbl, ok := v.(*lua.LTable)
+		switch {
+		case !eventNamePattern.MatchString(name):
+			err = fmt.Errorf("events.%s: event names must be lowercase letters, digits and underscores", name)
+			return
+		case slices.Contains(helperTags, name):
+			err = fmt.Errorf("events.%s: the name is taken by the built-in %s() helper", name, name)
+			return
+		case !ok:
+			err = fmt.Errorf("events.%s must be a table with an apply function", name)
+			return
+		}
+
+		apply, ok := tbl.RawGetString("apply").(*lua.LFunction)
+		if !ok {
+			err = fmt.Errorf("events.%s: apply must be a function(state, payload)", name)
+			return
+		}
+		def := EventDef{Name: name, Apply: apply}
+		switch msg := tbl.RawGetString("message").(type) {
+		case lua.LString:
+			def.Message = string(msg)
+		case *lua.LFunction:
+			def.Message = msg
+		}
+		events[name] = def
+	})
+	return events, err
+}
@@ -0,0 +1,124 @@
+package engine
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const reducerManifest = `
+events = {
+    bleed = {
+        apply = function(state, payload)
+            local hp = state.entities[payload.who].spent.hp or 0
+            return {
+                set_attr("spent", "hp", hp + payload.amount),
+                condition("bleeding"),
+            }
+        end,
+        message = function(payload) return payload.who .. " bleeds for " .. payload.amount end,
+    },
+    echo = {
+        apply = function(state, payload) return emit("bleed", payload) end,
+        message = "echo",
+    },
+}
+
+commands = {
+    slash = {
+        name = "slash",
+        params = { { name = "to", type = "target" } },
+        targets = { steps = { { name = "wound", value = function()
+            return emit("bleed", { who = target.id, amount = 3 })
+        end } } },
+    },
+    shout = {
+        name = "shout",
+        game = { steps = { { name = "yell", value = "emit('yell', { loud = true })" } } },
+    },
+    echo = {
+        name = "echo",
+        game = { steps = { { name = "echo", value = "emit('echo', { who = 'goblin', amount = 1 })" } } },
+    },
+}
+`
+
+func loadReducerManifest(t *testing.T, source string) (*Manifest, *LuaEvaluator) {
+	t.Helper()
+	dir := t.TempDir()
+	writeLuaFiles(t, dir, map[string]string{"manifest.lua": source})
+	eval, err := NewLuaEvaluator(mockRoll)
+	require.NoError(t, err)
+	t.Cleanup(eval.Close)
+	m, err := eval.LoadManifestLua(filepath.Join(dir, "manifest.lua"))
+	require.NoError(t, err)
+	return m, eval
+}
+
+func TestDeclaredEvent_AppliesThroughTheReducer(t *testing.T) {
+	m, eval := loadReducerManifest(t, reducerManifest)
+	state := testState()
+	state.Entities["goblin"].Resources["hp"] = 7
+	BindEvents(state, m, eval)
+
+	events, err := ExecuteCommand("slash", "fighter", []string{"goblin"}, map[string]any{"to": "goblin"}, state, m, eval)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+
+	bleed, ok := events[0].(*DeclaredEvent)
+	require.True(t, ok, "expected a DeclaredEvent, got %T", events[0])
+	assert.Equal(t, "bleed", bleed.Type())
+	assert.Equal(t, "goblin", bleed.TargetID)
+	assert.Equal(t, "goblin bleeds for 3", bleed.Message())
+
+	require.NoError(t, bleed.Apply(state))
+	require.NoError(t, bleed.Apply(state))
+	assert.Equal(t, 6, state.Entities["goblin"].Spent["hp"])
+	assert.Equal(t, []string{"bleeding"}, state.Entities["goblin"].Conditions)
+	assert.NotContains(t, state.Metadata, "bleed")
+}
+
+func TestDeclaredEvent_UndeclaredTypesStayCustomEvents(t *testing.T) {
+	m, eval := loadReducerManifest(t, reducerManifest)
+	state := testState()
+	BindEvents(state, m, eval)
+
+	events, err := ExecuteCommand("shout", "fighter", nil, nil, state, m, eval)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.IsType(t, &CustomEvent{}, events[0])
+}
+
+func TestDeclaredEvent_Errors(t *testing.T) {
+	m, eval := loadReducerManifest(t, reducerManifest)
+	state := testState()
+
+	// Not bound: the state does not know the type
+	err := (&DeclaredEvent{EventType: "bleed"}).Apply(state)
+	assert.ErrorContains(t, err, "event type bleed is not declared in the manifest")
+
+	BindEvents(state, m, eval)
+	events, err := ExecuteCommand("echo", "fighter", nil, nil, state, m, eval)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.ErrorContains(t, events[0].Apply(state), "event echo: a reducer cannot produce the declared event bleed")
+}
+
+func TestParseEvents_RejectsInvalidDeclarations(t *testing.T) {
+	for source, msg := range map[string]string{
+		`events = { spend = { apply = function() end } }`:   "the name is taken by the built-in spend() helper",
+		`events = { Bleed = { apply = function() end } }`:   "event names must be lowercase",
+		`events = { bleed = { message = "no apply" } }`:     "apply must be a function",
+		`events = { bleed = function(state, payload) end }`: "must be a table with an apply function",
+	} {
+		dir := t.TempDir()
+		writeLuaFiles(t, dir, map[string]string{"manifest.lua": source + "\ncommands = {}"})
+		eval, err := NewLuaEvaluator(nil)
+		require.NoError(t, err)
+		_, err = eval.LoadManifestLua(filepath.Join(dir, "manifest.lua"))
+		assert.ErrorContains(t, err, msg, source)
+		eval.Close()
+	}
+}
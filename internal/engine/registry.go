@@ -0,0 +1,120 @@
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// eventTypes maps the type names of the log to constructors of the empty events their data
+// decodes into. The event types a manifest declares are not registered here, since each session
+// has its own manifest; see CheckDecodable.
+var eventTypes = struct {
+	sync.RWMutex
+	m map[string]func() Event
+}{m: make(map[string]func() Event)}
+
+func init() {
+	for _, newEvent := range []func() Event{
+		func() Event { return &LoopEvent{} },
+		func() Event { return &LoopOrderAscendingEvent{} },
+		func() Event { return &LoopOrderEvent{} },
+		func() Event { return &ActorAddedEvent{} },
+		func() Event { return &AttributeChangedEvent{} },
+		func() Event { return &AddSpentEvent{} },
+		func() Event { return &ConditionEvent{} },
+		func() Event { return &AskIssuedEvent{} },
+		func() Event { return &HintEvent{} },
+		func() Event { return &DiceRolledEvent{} },
+		func() Event { return &MetadataChangedEvent{} },
+		func() Event { return &CheckEvent{} },
+		func() Event { return &CustomEvent{} },
+		func() Event { return &TurnEndedEvent{} },
+		func() Event { return &TurnStartedEvent{} },
+		func() Event { return &RoundStartedEvent{} },
+		func() Event { return &HookAddedEvent{} },
+		func() Event { return &HookFiredEvent{} },
+		func() Event { return &HookRemovedEvent{} },
+		func() Event { return &UndoRequestEvent{} },
+		func() Event { return &ReloadRequestEvent{} },
+		func() Event { return &EntityCreatedEvent{} },
+		func() Event { return &EntityRemovedEvent{} },
+		func() Event { return &SpawnRequestEvent{} },
+		func() Event { return &SecretEvent{} },
+		func() Event { return &SecretRevealedEvent{} },
+	} {
+		RegisterEvent(newEvent().Type(), newEvent)
+	}
+}
+
+// RegisterEvent makes events of a type decodable from the log: newEvent returns an empty event
+// of the type, which the logged data is decoded into. Registering a type again replaces it.
+func RegisterEvent(typeName string, newEvent func() Event) {
+	eventTypes.Lock()
+	defer eventTypes.Unlock()
+	eventTypes.m[typeName] = newEvent
+}
+
+// IsRegisteredEvent reports whether events of a type can be decoded from the log.
+func IsRegisteredEvent(typeName string) bool {
+	eventTypes.RLock()
+	defer eventTypes.RUnlock()
+	_, ok := eventTypes.m[typeName]
+	return ok
+}
+
+// CheckDecodable returns an error when the event, or an event a secret wraps, is of a type
+// that could not be decoded from the log. Declared events are checked against declares, which
+// reports whether the manifest of the log's session declares a type; it may be nil when none does.
+func CheckDecodable(evt Event, declares func(typeName string) bool) error {
+	if declared, ok := evt.(*DeclaredEvent); ok {
+		if declares == nil || !declares(declared.EventType) {
+			return fmt.Errorf("event type %s is not declared in the manifest", declared.EventType)
+		}
+	} else if !IsRegisteredEvent(evt.Type()) {
+		return fmt.Errorf("event type %s is not registered", evt.Type())
+	}
+	if secret, ok := evt.(*SecretEvent); ok {
+		for _, inner := range secret.Events {
+			if err := CheckDecodable(inner, declares); err != nil {
+				return fmt.Errorf("secret #%d: %w", secret.ID, err)
+			}
+		}
+	}
+	return nil
+}
+
+// DecodeEvent reconstructs an event from its type name and JSON data. Unregistered types named
+// like declared events decode as DeclaredEvent, so that a log outlives the declaration of its
+// event types; such an event fails when it is applied to a state that does not declare it.
+func DecodeEvent(typeName string, data json.RawMessage) (Event, error) {
+	eventTypes.RLock()
+	newEvent, ok := eventTypes.m[typeName]
+	eventTypes.RUnlock()
+
+	var evt Event
+	switch {
+	case ok:
+		evt = newEvent()
+	case IsDeclaredEventName(typeName):
+		evt = &DeclaredEvent{EventType: typeName}
+	default:
+		return nil, fmt.Errorf("unknown event type: %s", typeName)
+	}
+
+	if err := json.Unmarshal(data, evt); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal %s: %w", typeName, err)
+	}
+
+	// Secret events carry their wrapped events in serialized form
+	if secret, ok := evt.(*SecretEvent); ok {
+		for _, raw := range secret.Raw {
+			inner, err := DecodeEvent(raw.Type, raw.Data)
+			if err != nil {
+				return nil, fmt.Errorf("failed to unmarshal secret #%d: %w", secret.ID, err)
+			}
+			secret.Events = append(secret.Events, inner)
+		}
+	}
+	return evt, nil
+}
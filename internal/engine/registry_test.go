@@ -0,0 +1,52 @@
+package engine
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeEvent_RoundTripsEveryRegisteredType(t *testing.T) {
+	eventTypes.RLock()
+	constructors := make(map[string]func() Event, len(eventTypes.m))
+	for name, newEvent := range eventTypes.m {
+		constructors[name] = newEvent
+	}
+	eventTypes.RUnlock()
+
+	for name, newEvent := range constructors {
+		evt := newEvent()
+		assert.Equal(t, name, evt.Type())
+		data, err := json.Marshal(evt)
+		require.NoError(t, err, name)
+		decoded, err := DecodeEvent(name, data)
+		require.NoError(t, err, name)
+		assert.IsType(t, evt, decoded, name)
+	}
+}
+
+func TestDecodeEvent_DecodesSecretsAndDeclaredEvents(t *testing.T) {
+	secret := &SecretEvent{ID: 3, Events: []Event{
+		&ConditionEvent{ActorID: "goblin", Condition: "prone", Add: true},
+		&DeclaredEvent{EventType: "rage_started", ActorID: "goblin", Payload: map[string]any{"bonus": 2.0}},
+	}}
+	data, err := json.Marshal(secret)
+	require.NoError(t, err)
+
+	decoded, err := DecodeEvent("SecretEvent", data)
+	require.NoError(t, err)
+	require.IsType(t, &SecretEvent{}, decoded)
+	assert.Equal(t, secret.Events, decoded.(*SecretEvent).Events)
+
+	_, err = DecodeEvent("UnknownFutureEvent", []byte(`{}`))
+	assert.EqualError(t, err, "unknown event type: UnknownFutureEvent")
+	assert.Error(t, CheckDecodable(&DeclaredEvent{EventType: "rage_started"}, nil))
+
+	// Declared types are checked against the state whose manifest declares them
+	state := NewGameState()
+	BindEvents(state, &Manifest{Events: map[string]EventDef{"rage_started": {Name: "rage_started"}}}, nil)
+	assert.NoError(t, CheckDecodable(secret, state.Declares))
+	assert.ErrorContains(t, CheckDecodable(secret, NewGameState().Declares), "not declared")
+}
@@ -0,0 +1,88 @@
+package engine
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	lua "github.com/yuin/gopher-lua"
+)
+
+// moduleNamePattern accepts dotted module names such as "spells.fireball".
+var moduleNamePattern = regexp.MustCompile(`^[A-Za-z0-9_]+(\.[A-Za-z0-9_]+)*$`)
+
+// SetModulePaths sets the directories require() searches, in priority order (e.g., the campaign
+// directory before the world directory, so campaigns can override world modules).
+// When no paths are set, LoadManifestLua uses the manifest's own directory.
+func (ev *LuaEvaluator) SetModulePaths(dirs ...string) {
+	ev.modulePaths = dirs
+}
+
+// ModuleFiles returns the files of the modules require() has loaded or tried to load, in order.
+func (ev *LuaEvaluator) ModuleFiles() []string {
+	return ev.moduleFiles
+}
+
+// luaRequire is a sandboxed replacement for Lua's require: require("spells.fireball")
+// loads spells/fireball.lua (or spells/fireball/init.lua) from the first module path that has it.
+// Names cannot escape the module paths. Each module runs once; its return value (true when it
+// returns nothing) is cached and returned by later calls.
+func (ev *LuaEvaluator) luaRequire(L *lua.LState) int {
+	name := L.CheckString(1)
+	if cached, ok := ev.modules[name]; ok {
+		L.Push(cached)
+		return 1
+	}
+	if !moduleNamePattern.MatchString(name) {
+		L.RaiseError("invalid module name %q", name)
+		return 0
+	}
+	if ev.loading[name] {
+		L.RaiseError("module %q is required in a loop", name)
+		return 0
+	}
+
+	path := ev.findModule(name)
+	if path == "" {
+		L.RaiseError("module %q not found in: %s", name, strings.Join(ev.modulePaths, ", "))
+		return 0
+	}
+
+	ev.moduleFiles = append(ev.moduleFiles, path)
+	fn, err := L.LoadFile(path)
+	if err != nil {
+		L.RaiseError("error loading module %q: %s", name, luaErrorMessage(err))
+		return 0
+	}
+
+	ev.loading[name] = true
+	defer delete(ev.loading, name)
+	if err := L.CallByParam(lua.P{Fn: fn, NRet: 1, Protect: true}, lua.LString(name)); err != nil {
+		L.RaiseError("error running module %q: %s", name, luaErrorMessage(err))
+		return 0
+	}
+	result := L.Get(-1)
+	L.Pop(1)
+	if result == lua.LNil {
+		result = lua.LTrue
+	}
+
+	ev.modules[name] = result
+	L.Push(result)
+	return 1
+}
+
+// findModule returns the file that holds a module, or "" if no module path has it.
+func (ev *LuaEvaluator) findModule(name string) string {
+	rel := filepath.Join(strings.Split(name, ".")...)
+	for _, dir := range ev.modulePaths {
+		for _, candidate := range []string{rel + ".lua", filepath.Join(rel, "init.lua")} {
+			path := filepath.Join(dir, candidate)
+			if info, err := os.Stat(path); err == nil && !info.IsDir() {
+				return path
+			}
+		}
+	}
+	return ""
+}
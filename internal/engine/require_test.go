@@ -0,0 +1,125 @@
+package engine
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeLuaFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+}
+
+func TestRequire_LoadsAndCachesModules(t *testing.T) {
+	dir := t.TempDir()
+	writeLuaFiles(t, dir, map[string]string{
+		"manifest.lua": `
+local fireball = require("spells.fireball")
+local again = require("spells.fireball")
+local rules = require("rules")
+commands = {
+    fireball = { name = "fireball", help = fireball.help .. " " .. tostring(fireball == again) .. " " .. rules.loads },
+}
+`,
+		"spells/fireball.lua": `
+loads = (loads or 0) + 1
+return { help = "8d6 fire" }
+`,
+		"rules/init.lua": `return { loads = tostring(loads) }`,
+	})
+
+	eval, err := NewLuaEvaluator(nil)
+	require.NoError(t, err)
+	defer eval.Close()
+
+	m, err := eval.LoadManifestLua(filepath.Join(dir, "manifest.lua"))
+	require.NoError(t, err)
+	assert.Equal(t, "8d6 fire true 1", m.Commands["fireball"].Help)
+}
+
+func TestRequire_OverridesByPathOrder(t *testing.T) {
+	campaign, world := t.TempDir(), t.TempDir()
+	writeLuaFiles(t, world, map[string]string{
+		"manifest.lua": `commands = { rest = { name = "rest", help = require("rest").help } }`,
+		"rest.lua":     `return { help = "world rest" }`,
+	})
+	writeLuaFiles(t, campaign, map[string]string{
+		"rest.lua": `return { help = "campaign rest" }`,
+	})
+
+	eval, err := NewLuaEvaluator(nil)
+	require.NoError(t, err)
+	defer eval.Close()
+	eval.SetModulePaths(campaign, world)
+
+	m, err := eval.LoadManifestLua(filepath.Join(world, "manifest.lua"))
+	require.NoError(t, err)
+	assert.Equal(t, "campaign rest", m.Commands["rest"].Help)
+}
+
+func TestRequire_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		manifest string
+		files    map[string]string
+		wantErr  []string
+	}{
+		{
+			name:     "escaping the module paths",
+			manifest: `require("../secrets")`,
+			wantErr:  []string{`invalid module name "../secrets"`},
+		},
+		{
+			name:     "missing module",
+			manifest: `require("spells.wish")`,
+			wantErr:  []string{`module "spells.wish" not found`},
+		},
+		{
+			name:     "runtime error reports file and line",
+			manifest: `require("broken")`,
+			files:    map[string]string{"broken.lua": "local x = 1\nerror('bad rule')\n"},
+			wantErr:  []string{`error running module "broken"`, "broken.lua:2: bad rule"},
+		},
+		{
+			name:     "syntax error reports file and line",
+			manifest: `require("typo")`,
+			files:    map[string]string{"typo.lua": "return {\n  a = ,\n}\n"},
+			wantErr:  []string{`error loading module "typo"`, "typo.lua line:2"},
+		},
+		{
+			name:     "require loop",
+			manifest: `require("a")`,
+			files:    map[string]string{"a.lua": `require("b")`, "b.lua": `require("a")`},
+			wantErr:  []string{`module "a" is required in a loop`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			files := map[string]string{"manifest.lua": tt.manifest + "\ncommands = {}\n"}
+			for k, v := range tt.files {
+				files[k] = v
+			}
+			writeLuaFiles(t, dir, files)
+
+			eval, err := NewLuaEvaluator(nil)
+			require.NoError(t, err)
+			defer eval.Close()
+
+			_, err = eval.LoadManifestLua(filepath.Join(dir, "manifest.lua"))
+			require.Error(t, err)
+			for _, want := range tt.wantErr {
+				assert.ErrorContains(t, err, want)
+			}
+		})
+	}
+}
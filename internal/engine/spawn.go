@@ -0,0 +1,79 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MaxSpawnCount is the most entities a single spawn or clone creates.
+const MaxSpawnCount = 50
+
+// SpawnEntities instantiates count copies of a template entity and returns one EntityCreatedEvent
+// per copy. Copies start with no spent resources, conditions or hooks; when the template declares
+// classes.hit_dice (e.g., "2d6"), each copy rolls its own hp.
+//
+// IDs are derived from name (or the template name): a single copy takes the bare name when it is
+// free, otherwise copies are lettered (Goblin_A, Goblin_B, ...) skipping IDs already in use.
+func SpawnEntities(state *GameState, template *Entity, name string, count int, eval *LuaEvaluator) ([]Event, error) {
+	if template == nil {
+		return nil, fmt.Errorf("spawn requires a template")
+	}
+	if count < 1 {
+		count = 1
+	}
+	if name == "" {
+		name = template.Classes["template"]
+	}
+	if name == "" {
+		name = template.Name
+	}
+	if name == "" {
+		name = template.ID
+	}
+	base := strings.ReplaceAll(strings.TrimSpace(name), " ", "_")
+	if base == "" {
+		return nil, fmt.Errorf("spawn requires a name for the new entities")
+	}
+
+	ids := spawnIDs(state, base, count)
+	events := make([]Event, 0, len(ids))
+	for _, id := range ids {
+		ent := template.Clone()
+		ent.ID = id
+		ent.Name = strings.ReplaceAll(id, "_", " ")
+		ent.Classes["template"] = name
+		ent.Spent = make(map[string]int)
+		ent.Conditions = make([]string, 0)
+		ent.Hooks = make(map[string]Hook)
+
+		if hd := ent.Classes["hit_dice"]; hd != "" {
+			ent.Resources["hp"] = max(eval.roll(hd), 1)
+		}
+		events = append(events, &EntityCreatedEvent{Entity: ent})
+	}
+	return events, nil
+}
+
+// spawnIDs allocates count unused entity IDs for the given base name.
+func spawnIDs(state *GameState, base string, count int) []string {
+	if _, taken := state.Entities[base]; count == 1 && !taken {
+		return []string{base}
+	}
+	ids := make([]string, 0, count)
+	for i := 0; len(ids) < count; i++ {
+		id := base + "_" + letterSuffix(i)
+		if _, taken := state.Entities[id]; !taken {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// letterSuffix converts a zero-based index into A, B, ..., Z, AA, AB, ...
+func letterSuffix(i int) string {
+	s := ""
+	for i++; i > 0; i = (i - 1) / 26 {
+		s = string(rune('A'+(i-1)%26)) + s
+	}
+	return s
+}
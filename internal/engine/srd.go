@@ -0,0 +1,50 @@
+package engine
+
+import (
+	"errors"
+
+	lua "github.com/yuin/gopher-lua"
+)
+
+// DataSource looks up a reference record, such as an SRD spell, by category and index.
+// It returns an error wrapping ErrRecordNotFound when the record does not exist.
+type DataSource func(category, index string) (map[string]any, error)
+
+// ErrRecordNotFound marks DataSource lookups of records that do not exist.
+var ErrRecordNotFound = errors.New("record not found")
+
+// SetDataSource sets where srd() looks records up. Previously cached lookups are dropped.
+func (ev *LuaEvaluator) SetDataSource(source DataSource) {
+	ev.dataSource = source
+	ev.records = make(map[[2]string]map[string]any)
+}
+
+// luaSRD exposes reference data to Lua: srd("spells", "fireball") -> table, or nil and a message.
+// Lookups (including misses) are cached, and each call returns a fresh copy of the record.
+func (ev *LuaEvaluator) luaSRD(L *lua.LState) int {
+	category, index := L.CheckString(1), L.CheckString(2)
+	if ev.dataSource == nil {
+		L.RaiseError("srd: no data source is configured")
+		return 0
+	}
+
+	key := [2]string{category, index}
+	record, cached := ev.records[key]
+	if !cached {
+		var err error
+		record, err = ev.dataSource(category, index)
+		if err != nil && !errors.Is(err, ErrRecordNotFound) {
+			L.RaiseError("srd: %s", err.Error())
+			return 0
+		}
+		ev.records[key] = record
+	}
+
+	if record == nil {
+		L.Push(lua.LNil)
+		L.Push(lua.LString("no " + category + " record named " + index))
+		return 2
+	}
+	L.Push(goValueToLua(L, record))
+	return 1
+}
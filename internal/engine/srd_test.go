@@ -0,0 +1,63 @@
+package engine
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSRDLookup(t *testing.T) {
+	eval, err := NewLuaEvaluator(mockRoll)
+	require.NoError(t, err)
+	defer eval.Close()
+
+	lookups := 0
+	eval.SetDataSource(func(category, index string) (map[string]any, error) {
+		lookups++
+		switch {
+		case category == "spells" && index == "fireball":
+			return map[string]any{
+				"name":   "Fireball",
+				"level":  3,
+				"damage": map[string]any{"damage_at_slot_level": map[string]any{"3": "8d6"}},
+			}, nil
+		case category == "broken":
+			return nil, fmt.Errorf("failed to decode yaml reference broken/%s", index)
+		}
+		return nil, fmt.Errorf("no file: %w", ErrRecordNotFound)
+	})
+
+	result, err := eval.Eval("srd('spells', 'fireball').damage.damage_at_slot_level['3']", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "8d6", result)
+
+	// Repeated lookups are cached, and each returns a fresh copy
+	result, err = eval.Eval("(function() srd('spells', 'fireball').level = 9; return srd('spells', 'fireball').level end)()", nil)
+	require.NoError(t, err)
+	assert.Equal(t, 3, result)
+	assert.Equal(t, 1, lookups)
+
+	// Missing records return nil and a message; misses are cached too
+	result, err = eval.Eval("select(2, srd('spells', 'wish'))", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "no spells record named wish", result)
+	result, err = eval.Eval("srd('spells', 'wish') == nil", nil)
+	require.NoError(t, err)
+	assert.Equal(t, true, result)
+	assert.Equal(t, 2, lookups)
+
+	// Other errors are raised
+	_, err = eval.Eval("srd('broken', 'x')", nil)
+	assert.ErrorContains(t, err, "srd: failed to decode yaml reference broken/x")
+}
+
+func TestSRDLookup_NoDataSource(t *testing.T) {
+	eval, err := NewLuaEvaluator(mockRoll)
+	require.NoError(t, err)
+	defer eval.Close()
+
+	_, err = eval.Eval("srd('spells', 'fireball')", nil)
+	assert.ErrorContains(t, err, "no data source is configured")
+}
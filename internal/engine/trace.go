@@ -0,0 +1,177 @@
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// Kinds of trace nodes.
+const (
+	TraceCommand = "command"
+	TracePrereq  = "prereq"
+	TracePhase   = "phase"
+	TraceStep    = "step"
+	TraceHook    = "hook"
+	TraceRoll    = "roll"
+	TraceEvent   = "event"
+)
+
+// Trace records how a command was evaluated: its prereqs, phases, steps and the hooks it
+// triggered, each with its input, result, the dice rolled and the events emitted. Record one by
+// setting it on the evaluator with SetTrace. A nil *Trace records nothing.
+type Trace struct {
+	Root    *TraceNode
+	stack   []*TraceNode
+	catalog *Catalog // renders the messages of the events
+}
+
+// TraceNode is one evaluation in a trace. Input holds the values the formula could read:
+// the command parameters, the actor and target, and earlier step results.
+type TraceNode struct {
+	Kind     string         `json:"kind"`
+	Name     string         `json:"name,omitempty"`
+	Input    map[string]any `json:"input,omitempty"`
+	Result   any            `json:"result,omitempty"`
+	Error    string         `json:"error,omitempty"`
+	Children []*TraceNode   `json:"children,omitempty"`
+}
+
+// NewTrace starts a trace for a command as typed.
+func NewTrace(input string) *Trace {
+	root := &TraceNode{Kind: TraceCommand, Name: input}
+	return &Trace{Root: root, stack: []*TraceNode{root}}
+}
+
+// SetTrace makes the evaluator record into t until it is set to nil.
+func (ev *LuaEvaluator) SetTrace(t *Trace) {
+	ev.trace = t
+	if t != nil {
+		t.catalog = ev.catalog
+	}
+}
+
+// Fail records the error the command failed with.
+func (t *Trace) Fail(err error) {
+	if t == nil || err == nil {
+		return
+	}
+	t.Root.Error = err.Error()
+}
+
+// MarshalJSON encodes the trace as its root node.
+func (t *Trace) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.Root)
+}
+
+// open adds a node under the current one and makes it current.
+func (t *Trace) open(kind, name string, input map[string]any) {
+	if t == nil {
+		return
+	}
+	node := &TraceNode{Kind: kind, Name: name, Input: input}
+	top := t.stack[len(t.stack)-1]
+	top.Children = append(top.Children, node)
+	t.stack = append(t.stack, node)
+}
+
+// close records the outcome of the current node and its events, and returns to its parent.
+func (t *Trace) close(result any, err error, events ...Event) {
+	if t == nil || len(t.stack) == 1 {
+		return
+	}
+	node := t.stack[len(t.stack)-1]
+	node.Result = result
+	if err != nil {
+		node.Error = err.Error()
+	}
+	for _, evt := range events {
+		node.Children = append(node.Children, &TraceNode{Kind: TraceEvent, Name: evt.Type(), Result: MessageIn(evt, t.catalog)})
+	}
+	t.stack = t.stack[:len(t.stack)-1]
+}
+
+// add records a leaf node, such as a dice roll, under the current node.
+func (t *Trace) add(kind, name string, result any) {
+	if t == nil {
+		return
+	}
+	top := t.stack[len(t.stack)-1]
+	top.Children = append(top.Children, &TraceNode{Kind: kind, Name: name, Result: result})
+}
+
+// traceInput collects the values a formula can read, copying the result maps since later steps
+// keep adding to them. Empty values are left out.
+func traceInput(actorID, targetID string, params map[string]any, results ...map[string]any) map[string]any {
+	input := make(map[string]any)
+	if actorID != "" {
+		input["actor"] = actorID
+	}
+	if targetID != "" {
+		input["target"] = targetID
+	}
+	if len(params) > 0 {
+		input["command"] = maps.Clone(params)
+	}
+	for i, name := range []string{"game", "targets", "actor_results"} {
+		if i < len(results) && len(results[i]) > 0 {
+			input[name] = maps.Clone(results[i])
+		}
+	}
+	return input
+}
+
+// String renders the trace as a tree, one node per line.
+func (t *Trace) String() string {
+	var b strings.Builder
+	writeTraceNode(&b, t.Root, "", "")
+	return b.String()
+}
+
+func writeTraceNode(b *strings.Builder, node *TraceNode, first, rest string) {
+	b.WriteString(first + node.Kind)
+	if node.Name != "" {
+		b.WriteString(" " + node.Name)
+	}
+	switch {
+	case node.Error != "":
+		b.WriteString(" ✗ " + node.Error)
+	case node.Kind == TraceEvent:
+		b.WriteString(": " + fmt.Sprint(node.Result))
+	case node.Result != nil:
+		b.WriteString(" = " + formatTraceValue(node.Result))
+	}
+	b.WriteString("\n")
+
+	if len(node.Input) > 0 {
+		pipe := "  "
+		if len(node.Children) > 0 {
+			pipe = "│ "
+		}
+		var fields []string
+		for _, k := range slices.Sorted(maps.Keys(node.Input)) {
+			fields = append(fields, k+"="+formatTraceValue(node.Input[k]))
+		}
+		b.WriteString(rest + pipe + "  with " + strings.Join(fields, " ") + "\n")
+	}
+	for i, child := range node.Children {
+		if i == len(node.Children)-1 {
+			writeTraceNode(b, child, rest+"└─ ", rest+"   ")
+		} else {
+			writeTraceNode(b, child, rest+"├─ ", rest+"│  ")
+		}
+	}
+}
+
+// formatTraceValue renders a value compactly, as JSON when it can.
+func formatTraceValue(v any) string {
+	if s, ok := v.(string); ok {
+		return fmt.Sprintf("%q", s)
+	}
+	if data, err := json.Marshal(v); err == nil {
+		return string(data)
+	}
+	return fmt.Sprint(v)
+}
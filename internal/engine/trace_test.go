@@ -0,0 +1,114 @@
+package engine
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// traceKinds lists the kind and name of every node under n, depth first.
+func traceKinds(n *TraceNode) []string {
+	var out []string
+	for _, c := range n.Children {
+		out = append(out, c.Kind+" "+c.Name)
+		out = append(out, traceKinds(c)...)
+	}
+	return out
+}
+
+func TestTrace_RecordsPrereqsPhasesStepsAndEvents(t *testing.T) {
+	m := testManifest()
+	state := testState()
+	eval, err := NewLuaEvaluator(mockRoll)
+	require.NoError(t, err)
+
+	trace := NewTrace("grapple by: fighter to: goblin")
+	eval.SetTrace(trace)
+	_, err = ExecuteCommand("grapple", "fighter", []string{"goblin"},
+		map[string]any{"to": "goblin"}, state, m, eval)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"prereq check_action",
+		"phase game",
+		"step contest",
+		"event MetadataChangedEvent",
+		"phase targets goblin",
+		"step grappled",
+		"event ConditionEvent",
+		"phase actor",
+		"step consume_action",
+		"event AddSpentEvent",
+	}, traceKinds(trace.Root))
+
+	prereq := trace.Root.Children[0]
+	assert.Equal(t, true, prereq.Result)
+	assert.Equal(t, "fighter", prereq.Input["actor"])
+
+	grappled := trace.Root.Children[2].Children[0]
+	assert.Equal(t, "goblin", grappled.Input["target"])
+	assert.Contains(t, grappled.Input, "game")
+}
+
+func TestTrace_RecordsFailuresAndRolls(t *testing.T) {
+	m := testManifest()
+	state := testState()
+	state.Entities["fighter"].Spent["actions"] = 1
+	eval, err := NewLuaEvaluator(mockRoll)
+	require.NoError(t, err)
+
+	trace := NewTrace("grapple by: fighter to: goblin")
+	eval.SetTrace(trace)
+	_, err = ExecuteCommand("grapple", "fighter", []string{"goblin"},
+		map[string]any{"to": "goblin"}, state, m, eval)
+	require.Error(t, err)
+	trace.Fail(err)
+
+	require.Len(t, trace.Root.Children, 1)
+	assert.Equal(t, false, trace.Root.Children[0].Result)
+	assert.Equal(t, "no actions remaining", trace.Root.Error)
+
+	trace = NewTrace("roll")
+	eval.SetTrace(trace)
+	result, err := eval.Eval("roll('2d6') + 1", nil)
+	require.NoError(t, err)
+	assert.Equal(t, 11, result)
+	require.Len(t, trace.Root.Children, 1)
+	assert.Equal(t, &TraceNode{Kind: TraceRoll, Name: "2d6", Result: 10}, trace.Root.Children[0])
+}
+
+func TestTrace_Rendering(t *testing.T) {
+	trace := NewTrace("strike")
+	trace.open(TraceStep, "damage", map[string]any{"actor": "hero"})
+	trace.add(TraceRoll, "1d8", 5)
+	trace.close(5, nil, &HintEvent{MessageStr: "ouch"})
+	trace.open(TraceStep, "bonus", nil)
+	trace.close(nil, errors.New("boom"))
+
+	assert.Equal(t, `command strike
+├─ step damage = 5
+│  │   with actor="hero"
+│  ├─ roll 1d8 = 5
+│  └─ event HintEvent: ouch
+└─ step bonus ✗ boom
+`, trace.String())
+
+	data, err := json.Marshal(trace)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"kind":"command","name":"strike","children":[
+		{"kind":"step","name":"damage","input":{"actor":"hero"},"result":5,"children":[
+			{"kind":"roll","name":"1d8","result":5},
+			{"kind":"event","name":"HintEvent","result":"ouch"}]},
+		{"kind":"step","name":"bonus","error":"boom"}]}`, string(data))
+}
+
+func TestTrace_NilRecordsNothing(t *testing.T) {
+	var trace *Trace
+	trace.open(TraceStep, "x", nil)
+	trace.add(TraceRoll, "1d6", 3)
+	trace.close(nil, nil)
+	trace.Fail(errors.New("boom"))
+}
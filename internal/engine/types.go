@@ -4,8 +4,11 @@
 package engine
 
 import (
+	"encoding/json"
 	"fmt"
+	"maps"
 	"slices"
+	"strings"
 )
 
 // --- Manifest model ---
@@ -30,36 +33,72 @@ type PrereqStep struct {
 // The Value is a Lua closure that returns either a plain value (stored as a step result)
 // or a tagged table (dispatched as an event via the helper functions).
 type GameStep struct {
-	Name  string `yaml:"name"`
-	Value any    `yaml:"value"`
+	Name   string `yaml:"name"`
+	Value  any    `yaml:"value"`
+	Secret bool   `yaml:"secret"` // events produced by this step are only shown to the GM
 }
 
 // HookDef defines a dynamic hook in a command phase.
+// Repeats controls how often the hook fires before it is removed: "once" (the default),
+// "always", or "count" together with Count. StopWhen is an optional predicate evaluated
+// before each firing; when it returns true the hook is removed without running.
 type HookDef struct {
-	Name  string `yaml:"name"`
-	Type  string `yaml:"type"`
-	Value any    `yaml:"value"`
+	Name     string `yaml:"name"`
+	Type     string `yaml:"type"`
+	Value    any    `yaml:"value"`
+	Repeats  string `yaml:"repeats"`
+	Count    int    `yaml:"count"`
+	StopWhen any    `yaml:"stop_when"`
 }
 
+// Hook repeat policies.
+const (
+	HookRepeatOnce   = "once"
+	HookRepeatAlways = "always"
+	HookRepeatCount  = "count"
+)
+
 // CommandPhase represents a block of execution in a command (game, targets, or actor).
 type CommandPhase struct {
 	Steps []GameStep `yaml:"steps"`
 	Hooks []HookDef  `yaml:"hooks"`
 }
 
+// Command phase names, as used in manifests and hook references.
+const (
+	PhaseGame    = "game"
+	PhaseTargets = "targets"
+	PhaseActor   = "actor"
+)
+
+// Phase returns the command phase with the given name, or nil if the name is unknown.
+func (c *CommandDef) Phase(name string) *CommandPhase {
+	switch name {
+	case PhaseGame:
+		return &c.Game
+	case PhaseTargets:
+		return &c.Targets
+	case PhaseActor:
+		return &c.Actor
+	}
+	return nil
+}
+
 // CommandDef is the structured definition of a manifest-driven command.
 // It separates concerns into distinct phases: parameter validation, prerequisites,
 // game logic, per-target logic, and actor-affecting logic.
 type CommandDef struct {
-	Name    string       `yaml:"name"`
-	Params  []ParamDef   `yaml:"params"`
-	Prereq  []PrereqStep `yaml:"prereq"`
-	Hint    string       `yaml:"hint"`
-	Help    string       `yaml:"help"`
-	Error   string       `yaml:"error"` // Usage string shown on invalid input
-	Game    CommandPhase `yaml:"game"`
-	Targets CommandPhase `yaml:"targets"`
-	Actor   CommandPhase `yaml:"actor"`
+	Name    string         `yaml:"name"`
+	Params  []ParamDef     `yaml:"params"`
+	Prereq  []PrereqStep   `yaml:"prereq"`
+	Hint    string         `yaml:"hint"`
+	Help    string         `yaml:"help"`
+	Error   string         `yaml:"error"` // Usage string shown on invalid input
+	Game    CommandPhase   `yaml:"game"`
+	Targets CommandPhase   `yaml:"targets"`
+	Actor   CommandPhase   `yaml:"actor"`
+	Layer   string         `yaml:"-"` // Overlay that last added or changed the command; empty for the base manifest
+	Format  map[string]any `yaml:"-"` // Formatters of the command's events by event type
 }
 
 // Restrictions defines cross-cutting rules that apply to multiple commands.
@@ -70,11 +109,44 @@ type Restrictions struct {
 	GMCommands []string `yaml:"gm_commands"`
 }
 
+// Visibility declares what players and spectators may see of entities they do not control.
+// The GM always sees the full state; see ProjectState.
+type Visibility struct {
+	// Resources lists the resources shown to other viewers, as labels instead of numbers
+	// (e.g., hp as "bloodied" or "healthy"). Resources that are not listed are hidden.
+	Resources map[string][]ResourceBand `yaml:"resources"`
+	// HiddenConditions are conditions only the GM and the entity's player can see.
+	HiddenConditions []string `yaml:"hidden_conditions"`
+	// PublicMetadata lists the metadata keys visible to everyone; all others are GM-only.
+	PublicMetadata []string `yaml:"public_metadata"`
+}
+
+// ResourceBand labels a range of a resource's remaining fraction (current / max).
+// Bands are checked in order; the first one whose AtMost is not below the fraction applies,
+// and a band without AtMost matches anything.
+type ResourceBand struct {
+	AtMost *float64 `yaml:"at_most"`
+	Label  string   `yaml:"label"`
+}
+
 // Manifest is the top-level structure of a campaign manifest YAML file.
 // It contains all command definitions and cross-cutting restrictions.
 type Manifest struct {
 	Restrictions Restrictions          `yaml:"restrictions"`
+	Visibility   Visibility            `yaml:"visibility"`
 	Commands     map[string]CommandDef `yaml:"commands"`
+	Events       map[string]EventDef   `yaml:"events"`
+	Formatters   map[string]any        `yaml:"-"` // Lua functions narrating events by event type
+	Catalog      *Catalog              `yaml:"-"` // messages of the manifest's locale; see LocalizeManifest
+}
+
+// EventDef declares a custom event type. Apply is the Lua reducer called with a view of the state
+// and the event payload; it returns the helper results (e.g., condition(), set_attr()) that make
+// up the event's effect. Message is a string, or a function of the payload, shown for the event.
+type EventDef struct {
+	Name    string `yaml:"name"`
+	Apply   any    `yaml:"apply"`
+	Message any    `yaml:"message"`
 }
 
 // --- Entity model ---
@@ -85,6 +157,7 @@ type Manifest struct {
 type Entity struct {
 	ID            string            `json:"id" yaml:"id"`
 	Name          string            `json:"name" yaml:"name"`
+	Extends       string            `json:"extends,omitempty" yaml:"extends"`   // template this entity inherits from
 	Types         []string          `json:"types" yaml:"types"`                 // e.g., "monster", "undead"
 	Classes       map[string]string `json:"classes" yaml:"classes"`             // e.g., "size": "medium"
 	Stats         map[string]int    `json:"stats" yaml:"stats"`                 // e.g., "str": 16
@@ -95,6 +168,24 @@ type Entity struct {
 	Statuses      map[string]string `json:"statuses" yaml:"statuses"`           // e.g., "concentrating": "true"
 	Inventory     map[string]int    `json:"inventory" yaml:"inventory"`         // items and counts
 	Hooks         map[string]Hook   `json:"hooks" yaml:"hooks"`                 // dynamic hooks keyed by their Name
+	Actions       map[string]Action `json:"actions" yaml:"actions"`             // e.g., "scimitar": {attack_bonus: 4, ...}
+}
+
+// Action describes something an entity can do, such as a weapon attack or a breath weapon.
+type Action struct {
+	Name        string         `json:"name" yaml:"name"`
+	Desc        string         `json:"desc,omitempty" yaml:"desc"`
+	AttackBonus int            `json:"attack_bonus,omitempty" yaml:"attack_bonus"`
+	Damage      []ActionDamage `json:"damage,omitempty" yaml:"damage"`
+	DC          int            `json:"dc,omitempty" yaml:"dc"`                 // saving throw difficulty, if any
+	DCAbility   string         `json:"dc_ability,omitempty" yaml:"dc_ability"` // e.g., "dex"
+	DCSuccess   string         `json:"dc_success,omitempty" yaml:"dc_success"` // e.g., "half", "none"
+}
+
+// ActionDamage is one damage roll of an action (e.g., 2d6+2 slashing).
+type ActionDamage struct {
+	Dice string `json:"dice" yaml:"dice"`
+	Type string `json:"type" yaml:"type"`
 }
 
 // NewEntity creates an Entity with all maps initialized to avoid nil-map panics.
@@ -112,9 +203,41 @@ func NewEntity(id, name string) *Entity {
 		Statuses:      make(map[string]string),
 		Inventory:     make(map[string]int),
 		Hooks:         make(map[string]Hook),
+		Actions:       make(map[string]Action),
 	}
 }
 
+// Clone returns a deep copy of the entity.
+func (e *Entity) Clone() *Entity {
+	c := &Entity{
+		ID:            e.ID,
+		Name:          e.Name,
+		Extends:       e.Extends,
+		Types:         append([]string(nil), e.Types...),
+		Classes:       make(map[string]string, len(e.Classes)),
+		Stats:         make(map[string]int, len(e.Stats)),
+		Resources:     make(map[string]int, len(e.Resources)),
+		Spent:         make(map[string]int, len(e.Spent)),
+		Conditions:    append([]string(nil), e.Conditions...),
+		Proficiencies: make(map[string]int, len(e.Proficiencies)),
+		Statuses:      make(map[string]string, len(e.Statuses)),
+		Inventory:     make(map[string]int, len(e.Inventory)),
+		Hooks:         make(map[string]Hook, len(e.Hooks)),
+		Actions:       make(map[string]Action, len(e.Actions)),
+	}
+	maps.Copy(c.Classes, e.Classes)
+	maps.Copy(c.Stats, e.Stats)
+	maps.Copy(c.Resources, e.Resources)
+	maps.Copy(c.Spent, e.Spent)
+	maps.Copy(c.Proficiencies, e.Proficiencies)
+	maps.Copy(c.Statuses, e.Statuses)
+	maps.Copy(c.Inventory, e.Inventory)
+	maps.Copy(c.Hooks, e.Hooks)
+	maps.Copy(c.Actions, e.Actions)
+	normalizeEntity(c)
+	return c
+}
+
 // --- Game state ---
 
 // Loop represents an ordered sequence of actors taking turns (e.g., combat encounter).
@@ -130,12 +253,33 @@ type Loop struct {
 }
 
 // Hook represents an active hook in the game state.
+// A hook is persisted as a reference to its manifest definition (SourceCommand, Phase and Name)
+// together with the context captured when it was created. Value and StopWhen hold the live
+// Lua closures and are re-bound from the manifest with BindHooks after the state is rebuilt.
 type Hook struct {
-	Name          string `json:"name"`
-	Type          string `json:"type"`           // e.g., "next_turn", "next_round"
-	TargetID      string `json:"target_id"`      // The specific entity this hook watches (empty if global)
-	SourceCommand string `json:"source_command"` // The command that created this hook
-	Value         any    `json:"value"`          // The Lua closure representing the hook's logic
+	Name          string         `json:"name"`
+	Type          string         `json:"type"`             // e.g., "next_turn", "next_round"
+	TargetID      string         `json:"target_id"`        // The specific entity this hook watches (empty if global)
+	SourceCommand string         `json:"source_command"`   // The command that created this hook
+	Phase         string         `json:"phase"`            // The command phase declaring the hook: "game", "targets" or "actor"
+	ActorID       string         `json:"actor_id"`         // The actor that issued the source command
+	Params        map[string]any `json:"params,omitempty"` // The source command parameters
+	Repeats       string         `json:"repeats"`          // "once", "always" or "count"
+	Remaining     int            `json:"remaining"`        // Firings left when Repeats is "count"
+	Value         any            `json:"-"`                // The Lua closure representing the hook's logic
+	StopWhen      any            `json:"-"`                // Optional predicate that removes the hook when true
+}
+
+// exhausted reports whether the hook must be removed after its current firing.
+func (h Hook) exhausted() bool {
+	switch h.Repeats {
+	case HookRepeatAlways:
+		return false
+	case HookRepeatCount:
+		return h.Remaining <= 1
+	default:
+		return true
+	}
 }
 
 // GameState is the full projection of game state, built from applied events.
@@ -143,11 +287,17 @@ type GameState struct {
 	Entities map[string]*Entity `json:"entities"`
 	Loops    map[string]*Loop   `json:"loops"`
 	Metadata map[string]any     `json:"metadata"`
-	Hooks    map[string]Hook    `json:"hooks"` // Global hooks
+	Hooks    map[string]Hook    `json:"hooks"`   // Global hooks
+	Secrets  []Secret           `json:"secrets"` // GM-only results, in creation order (ID = index + 1)
 
 	// LastCommand tracks the name of the last successfully executed command,
 	// used by the "hint" hardcoded command.
 	LastCommand string `json:"last_command"`
+
+	// events are the manifest's event types, bound to an evaluator by BindEvents.
+	events map[string]boundEvent
+	// catalog renders the messages kept in the state, such as those of secrets; see BindEvents.
+	catalog *Catalog
 }
 
 // NewGameState creates a clean, empty game state with all maps initialized.
@@ -160,6 +310,40 @@ func NewGameState() *GameState {
 	}
 }
 
+// Clone returns a deep copy of the game state. Hook values and metadata values are shared.
+func (s *GameState) Clone() *GameState {
+	c := &GameState{
+		Entities:    make(map[string]*Entity, len(s.Entities)),
+		Loops:       make(map[string]*Loop, len(s.Loops)),
+		Metadata:    maps.Clone(s.Metadata),
+		Hooks:       maps.Clone(s.Hooks),
+		Secrets:     make([]Secret, 0, len(s.Secrets)),
+		LastCommand: s.LastCommand,
+		events:      s.events,
+		catalog:     s.catalog,
+	}
+	for id, ent := range s.Entities {
+		c.Entities[id] = ent.Clone()
+	}
+	for name, l := range s.Loops {
+		loop := *l
+		loop.Actors = slices.Clone(l.Actors)
+		loop.Order = maps.Clone(l.Order)
+		c.Loops[name] = &loop
+	}
+	for _, secret := range s.Secrets {
+		secret.Messages = slices.Clone(secret.Messages)
+		c.Secrets = append(c.Secrets, secret)
+	}
+	if c.Metadata == nil {
+		c.Metadata = make(map[string]any)
+	}
+	if c.Hooks == nil {
+		c.Hooks = make(map[string]Hook)
+	}
+	return c
+}
+
 // IsLoopActive returns whether a named loop is currently active.
 func (s *GameState) IsLoopActive(name string) bool {
 	if l, ok := s.Loops[name]; ok {
@@ -202,11 +386,12 @@ func (e *LoopEvent) Apply(state *GameState) error {
 	}
 	return nil
 }
-func (e *LoopEvent) Message() string {
+func (e *LoopEvent) Message() string { return e.MessageIn(nil) }
+func (e *LoopEvent) MessageIn(c *Catalog) string {
 	if e.Active {
-		return e.LoopName + " started"
+		return c.Text("event.loop.started", e.LoopName)
 	}
-	return e.LoopName + " ended"
+	return c.Text("event.loop.ended", e.LoopName)
 }
 
 // LoopOrderAscendingEvent sets whether a loop sorts actors in ascending order.
@@ -238,8 +423,9 @@ func (e *LoopOrderEvent) Apply(state *GameState) error {
 	}
 	return nil
 }
-func (e *LoopOrderEvent) Message() string {
-	return e.ActorID + " order set"
+func (e *LoopOrderEvent) Message() string { return e.MessageIn(nil) }
+func (e *LoopOrderEvent) MessageIn(c *Catalog) string {
+	return c.Text("event.loop_order", e.ActorID)
 }
 
 // ActorAddedEvent adds an actor to a named loop.
@@ -259,8 +445,9 @@ func (e *ActorAddedEvent) Apply(state *GameState) error {
 	}
 	return nil
 }
-func (e *ActorAddedEvent) Message() string {
-	return e.ActorID + " added to " + e.LoopName
+func (e *ActorAddedEvent) Message() string { return e.MessageIn(nil) }
+func (e *ActorAddedEvent) MessageIn(c *Catalog) string {
+	return c.Text("event.actor_added", e.ActorID, e.LoopName)
 }
 
 // AttributeChangedEvent modifies a specific field in an entity's data maps.
@@ -307,8 +494,9 @@ func (e *AttributeChangedEvent) Apply(state *GameState) error {
 	}
 	return nil
 }
-func (e *AttributeChangedEvent) Message() string {
-	return fmt.Sprintf("%s.%s.%s changed", e.ActorID, e.Section, e.Key)
+func (e *AttributeChangedEvent) Message() string { return e.MessageIn(nil) }
+func (e *AttributeChangedEvent) MessageIn(c *Catalog) string {
+	return c.Text("event.attribute_changed", e.ActorID, e.Section, e.Key)
 }
 
 // AddSpentEvent increments entity.Spent[Key] by Amount.
@@ -332,12 +520,13 @@ func (e *AddSpentEvent) Apply(state *GameState) error {
 	ent.Spent[e.Key] += amt
 	return nil
 }
-func (e *AddSpentEvent) Message() string {
+func (e *AddSpentEvent) Message() string { return e.MessageIn(nil) }
+func (e *AddSpentEvent) MessageIn(c *Catalog) string {
 	amt := e.Amount
 	if amt == 0 {
 		amt = 1
 	}
-	return fmt.Sprintf("%s spent %d %s", e.ActorID, amt, e.Key)
+	return c.Text("event.spent", e.ActorID, amt, e.Key)
 }
 
 // ConditionEvent adds or removes a condition from an entity.
@@ -369,11 +558,12 @@ func (e *ConditionEvent) Apply(state *GameState) error {
 	}
 	return nil
 }
-func (e *ConditionEvent) Message() string {
+func (e *ConditionEvent) Message() string { return e.MessageIn(nil) }
+func (e *ConditionEvent) MessageIn(c *Catalog) string {
 	if e.Add {
-		return fmt.Sprintf("%s is now %s", e.ActorID, e.Condition)
+		return c.Text("event.condition.added", e.ActorID, e.Condition)
 	}
-	return fmt.Sprintf("%s is no longer %s", e.ActorID, e.Condition)
+	return c.Text("event.condition.removed", e.ActorID, e.Condition)
 }
 
 // AskIssuedEvent freezes the game and requests input from a target.
@@ -391,8 +581,9 @@ func (e *AskIssuedEvent) Apply(state *GameState) error {
 	}
 	return nil
 }
-func (e *AskIssuedEvent) Message() string {
-	return fmt.Sprintf("Waiting for %s to respond", e.TargetID)
+func (e *AskIssuedEvent) Message() string { return e.MessageIn(nil) }
+func (e *AskIssuedEvent) MessageIn(c *Catalog) string {
+	return c.Text("event.ask", e.TargetID)
 }
 
 // HintEvent is a display-only message that is not persisted.
@@ -413,8 +604,9 @@ type DiceRolledEvent struct {
 
 func (e *DiceRolledEvent) Type() string                 { return "DiceRolledEvent" }
 func (e *DiceRolledEvent) Apply(state *GameState) error { return nil }
-func (e *DiceRolledEvent) Message() string {
-	return fmt.Sprintf("%s rolled %s = %d", e.ActorID, e.Dice, e.Result)
+func (e *DiceRolledEvent) Message() string              { return e.MessageIn(nil) }
+func (e *DiceRolledEvent) MessageIn(c *Catalog) string {
+	return c.Text("event.dice_rolled", e.ActorID, e.Dice, e.Result)
 }
 
 // MetadataChangedEvent stores or updates arbitrary data in global game metadata.
@@ -428,8 +620,9 @@ func (e *MetadataChangedEvent) Apply(state *GameState) error {
 	state.Metadata[e.Key] = e.Value
 	return nil
 }
-func (e *MetadataChangedEvent) Message() string {
-	return fmt.Sprintf("metadata.%s updated", e.Key)
+func (e *MetadataChangedEvent) Message() string { return e.MessageIn(nil) }
+func (e *MetadataChangedEvent) MessageIn(c *Catalog) string {
+	return c.Text("event.metadata_changed", e.Key)
 }
 
 // CheckEvent records the boolean result of an ability check or skill contest.
@@ -448,12 +641,12 @@ func (e *CheckEvent) Apply(state *GameState) error {
 	}
 	return nil
 }
-func (e *CheckEvent) Message() string {
-	result := "failed"
+func (e *CheckEvent) Message() string { return e.MessageIn(nil) }
+func (e *CheckEvent) MessageIn(c *Catalog) string {
 	if e.Passed {
-		result = "passed"
+		return c.Text("event.check.passed", e.ActorID, e.Check)
 	}
-	return fmt.Sprintf("%s %s check: %s", e.ActorID, e.Check, result)
+	return c.Text("event.check.failed", e.ActorID, e.Check)
 }
 
 // CustomEvent stores user-defined event payloads in GameState.Metadata.
@@ -468,8 +661,9 @@ func (e *CustomEvent) Apply(state *GameState) error {
 	state.Metadata[e.EventType] = e.Payload
 	return nil
 }
-func (e *CustomEvent) Message() string {
-	return fmt.Sprintf("custom event: %s", e.EventType)
+func (e *CustomEvent) Message() string { return e.MessageIn(nil) }
+func (e *CustomEvent) MessageIn(c *Catalog) string {
+	return c.Text("event.custom", e.EventType)
 }
 
 // TurnEndedEvent marks the end of an actor's turn in a loop.
@@ -483,8 +677,9 @@ func (e *TurnEndedEvent) Apply(state *GameState) error {
 	// Turn end is informational; state change happens in TurnStarted
 	return nil
 }
-func (e *TurnEndedEvent) Message() string {
-	return fmt.Sprintf("%s's turn ended", e.ActorID)
+func (e *TurnEndedEvent) Message() string { return e.MessageIn(nil) }
+func (e *TurnEndedEvent) MessageIn(c *Catalog) string {
+	return c.Text("event.turn_ended", e.ActorID)
 }
 
 // TurnStartedEvent advances the loop to the next actor's turn.
@@ -508,8 +703,9 @@ func (e *TurnStartedEvent) Apply(state *GameState) error {
 	}
 	return nil
 }
-func (e *TurnStartedEvent) Message() string {
-	return fmt.Sprintf("%s's turn (turn %d)", e.ActorID, e.Turn)
+func (e *TurnStartedEvent) Message() string { return e.MessageIn(nil) }
+func (e *TurnStartedEvent) MessageIn(c *Catalog) string {
+	return c.Text("event.turn_started", e.ActorID, e.Turn)
 }
 
 // RoundStartedEvent marks the beginning of a new round in a loop.
@@ -525,8 +721,9 @@ func (e *RoundStartedEvent) Apply(state *GameState) error {
 	}
 	return nil
 }
-func (e *RoundStartedEvent) Message() string {
-	return fmt.Sprintf("round %d started", e.Round)
+func (e *RoundStartedEvent) Message() string { return e.MessageIn(nil) }
+func (e *RoundStartedEvent) MessageIn(c *Catalog) string {
+	return c.Text("event.round_started", e.Round)
 }
 
 // HookAddedEvent registers a Hook onto either an Entity (if TargetID != "") or global GameState.
@@ -547,11 +744,12 @@ func (e *HookAddedEvent) Apply(state *GameState) error {
 	// If target isn't found, silently drop (entity might have been removed)
 	return nil
 }
-func (e *HookAddedEvent) Message() string {
+func (e *HookAddedEvent) Message() string { return e.MessageIn(nil) }
+func (e *HookAddedEvent) MessageIn(c *Catalog) string {
 	if e.TargetID == "" {
-		return fmt.Sprintf("Global hook %s added", e.Hook.Name)
+		return c.Text("event.hook_added.global", e.Hook.Name)
 	}
-	return fmt.Sprintf("Hook %s added to %s", e.Hook.Name, e.TargetID)
+	return c.Text("event.hook_added", e.Hook.Name, e.TargetID)
 }
 
 // HookRemovedEvent removes a Hook from either an Entity or global GameState.
@@ -571,8 +769,41 @@ func (e *HookRemovedEvent) Apply(state *GameState) error {
 	}
 	return nil
 }
-func (e *HookRemovedEvent) Message() string {
-	return fmt.Sprintf("Hook %s removed", e.HookName)
+func (e *HookRemovedEvent) Message() string { return e.MessageIn(nil) }
+func (e *HookRemovedEvent) MessageIn(c *Catalog) string {
+	return c.Text("event.hook_removed", e.HookName)
+}
+
+// HookFiredEvent records that a repeating hook ran and stays registered.
+// For counted hooks, Remaining holds the number of firings left after this one.
+type HookFiredEvent struct {
+	TargetID  string `json:"target_id"`
+	HookName  string `json:"hook_name"`
+	Remaining int    `json:"remaining"`
+}
+
+func (e *HookFiredEvent) Type() string { return "HookFiredEvent" }
+func (e *HookFiredEvent) Apply(state *GameState) error {
+	hooks := state.Hooks
+	if e.TargetID != "" {
+		ent, ok := state.Entities[e.TargetID]
+		if !ok {
+			return nil
+		}
+		hooks = ent.Hooks
+	}
+	if h, ok := hooks[e.HookName]; ok && h.Repeats == HookRepeatCount {
+		h.Remaining = e.Remaining
+		hooks[e.HookName] = h
+	}
+	return nil
+}
+func (e *HookFiredEvent) Message() string { return e.MessageIn(nil) }
+func (e *HookFiredEvent) MessageIn(c *Catalog) string {
+	if e.Remaining > 0 {
+		return c.Text("event.hook_fired.remaining", e.HookName, e.Remaining)
+	}
+	return c.Text("event.hook_fired", e.HookName)
 }
 
 // UndoRequestEvent signals the session to undo the event log.
@@ -585,18 +816,198 @@ type UndoRequestEvent struct {
 
 func (e *UndoRequestEvent) Apply(state *GameState) error { return nil }
 func (e *UndoRequestEvent) Type() string                 { return "UndoRequestEvent" }
-func (e *UndoRequestEvent) Message() string {
+func (e *UndoRequestEvent) Message() string              { return e.MessageIn(nil) }
+func (e *UndoRequestEvent) MessageIn(c *Catalog) string {
 	if e.Turn > 0 {
-		return fmt.Sprintf("Undo requested to turn %d.", e.Turn)
+		return c.Text("event.undo.turn", e.Turn)
 	}
 	if e.Round > 0 {
-		return fmt.Sprintf("Undo requested to round %d.", e.Round)
+		return c.Text("event.undo.round", e.Round)
+	}
+	return c.Text("event.undo.steps", e.Steps)
+}
+
+// ReloadRequestEvent signals the session to reload the manifest from disk.
+// It is intercepted by the session logic and never appended to state/log.
+type ReloadRequestEvent struct{}
+
+func (e *ReloadRequestEvent) Apply(state *GameState) error { return nil }
+func (e *ReloadRequestEvent) Type() string                 { return "ReloadRequestEvent" }
+func (e *ReloadRequestEvent) Message() string              { return e.MessageIn(nil) }
+func (e *ReloadRequestEvent) MessageIn(c *Catalog) string  { return c.Text("event.reload") }
+
+// EntityCreatedEvent adds a new entity to the game state (e.g., a spawned monster).
+type EntityCreatedEvent struct {
+	Entity *Entity `json:"entity"`
+}
+
+func (e *EntityCreatedEvent) Type() string { return "EntityCreatedEvent" }
+func (e *EntityCreatedEvent) Apply(state *GameState) error {
+	if e.Entity == nil || e.Entity.ID == "" {
+		return fmt.Errorf("entity created without an id")
+	}
+	ent := e.Entity.Clone()
+	state.Entities[ent.ID] = ent
+	return nil
+}
+func (e *EntityCreatedEvent) Message() string { return e.MessageIn(nil) }
+func (e *EntityCreatedEvent) MessageIn(c *Catalog) string {
+	if hp, ok := e.Entity.Resources["hp"]; ok {
+		return c.Text("event.entity_created.hp", e.Entity.ID, hp)
 	}
-	return fmt.Sprintf("Undo requested for %d step(s).", e.Steps)
+	return c.Text("event.entity_created", e.Entity.ID)
+}
+
+// EntityRemovedEvent removes an entity from the game state and from every loop it takes part in.
+type EntityRemovedEvent struct {
+	ActorID string `json:"actor_id"`
+}
+
+func (e *EntityRemovedEvent) Type() string { return "EntityRemovedEvent" }
+func (e *EntityRemovedEvent) Apply(state *GameState) error {
+	if _, ok := state.Entities[e.ActorID]; !ok {
+		return fmt.Errorf("entity %s not found", e.ActorID)
+	}
+	delete(state.Entities, e.ActorID)
+
+	for _, loop := range state.Loops {
+		removeLoopActor(loop, e.ActorID)
+	}
+	return nil
+}
+func (e *EntityRemovedEvent) Message() string { return e.MessageIn(nil) }
+func (e *EntityRemovedEvent) MessageIn(c *Catalog) string {
+	return c.Text("event.entity_removed", e.ActorID)
+}
+
+// removeLoopActor drops an actor from a loop, keeping the current turn on the same
+// actor when possible, or on the next one if the removed actor was current.
+func removeLoopActor(loop *Loop, actorID string) {
+	if !slices.Contains(loop.Actors, actorID) {
+		return
+	}
+	sorted := sortedActors(loop)
+	idx := slices.Index(sorted, actorID)
+
+	loop.Actors = slices.DeleteFunc(loop.Actors, func(id string) bool { return id == actorID })
+	delete(loop.Order, actorID)
+
+	if idx < loop.Current {
+		loop.Current--
+	}
+	if loop.Current >= len(loop.Actors) {
+		loop.Current = 0
+	}
+}
+
+// SpawnRequestEvent signals the session to instantiate entities from a template.
+// It is intercepted by the session logic, which resolves the template and
+// appends the resulting EntityCreatedEvents instead.
+type SpawnRequestEvent struct {
+	ActorID  string `json:"actor_id"`
+	Template string `json:"template"`
+	Name     string `json:"name,omitempty"`
+	Count    int    `json:"count"`
+}
+
+func (e *SpawnRequestEvent) Apply(state *GameState) error { return nil }
+func (e *SpawnRequestEvent) Type() string                 { return "SpawnRequestEvent" }
+func (e *SpawnRequestEvent) Message() string              { return e.MessageIn(nil) }
+func (e *SpawnRequestEvent) MessageIn(c *Catalog) string {
+	return c.Text("event.spawn", e.Count, e.Template)
+}
+
+// Secret records the messages of a GM-only result and whether they were shown to the players.
+type Secret struct {
+	ID       int      `json:"id"`
+	Messages []string `json:"messages"`
+	Revealed bool     `json:"revealed"`
+}
+
+// RawEvent is a serialized event, used to persist events nested inside other events.
+type RawEvent struct {
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+// SecretEvent wraps events whose results only the GM may see (e.g., a hidden Perception roll).
+// The wrapped events are applied normally; frontends shown to players must check IsSecret.
+// When decoded from JSON only Raw is filled, and the store decodes it back into Events.
+type SecretEvent struct {
+	ID     int        `json:"id"`
+	Events []Event    `json:"-"`
+	Raw    []RawEvent `json:"events"`
+}
+
+func (e *SecretEvent) Type() string { return "SecretEvent" }
+func (e *SecretEvent) Apply(state *GameState) error {
+	for _, evt := range e.Events {
+		if err := evt.Apply(state); err != nil {
+			return err
+		}
+	}
+	state.Secrets = append(state.Secrets, Secret{ID: e.ID, Messages: eventMessages(e.Events, state.catalog)})
+	return nil
+}
+func (e *SecretEvent) Message() string { return e.MessageIn(nil) }
+func (e *SecretEvent) MessageIn(c *Catalog) string {
+	return c.Text("event.secret", e.ID, strings.Join(eventMessages(e.Events, c), "; "))
+}
+
+// MarshalJSON serializes the wrapped events along with their types.
+func (e *SecretEvent) MarshalJSON() ([]byte, error) {
+	raw := make([]RawEvent, 0, len(e.Events))
+	for _, evt := range e.Events {
+		data, err := json.Marshal(evt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal secret %s: %w", evt.Type(), err)
+		}
+		raw = append(raw, RawEvent{Type: evt.Type(), Data: data})
+	}
+	type alias SecretEvent
+	return json.Marshal(&alias{ID: e.ID, Raw: raw})
+}
+
+// IsSecret reports whether an event must be hidden from players.
+func IsSecret(evt Event) bool {
+	_, ok := evt.(*SecretEvent)
+	return ok
+}
+
+// SecretRevealedEvent publishes a previously secret result to the players.
+type SecretRevealedEvent struct {
+	ID       int      `json:"id"`
+	Messages []string `json:"messages"`
+}
+
+func (e *SecretRevealedEvent) Type() string { return "SecretRevealedEvent" }
+func (e *SecretRevealedEvent) Apply(state *GameState) error {
+	for i := range state.Secrets {
+		if state.Secrets[i].ID == e.ID {
+			state.Secrets[i].Revealed = true
+			return nil
+		}
+	}
+	return fmt.Errorf("secret #%d not found", e.ID)
+}
+func (e *SecretRevealedEvent) Message() string { return e.MessageIn(nil) }
+func (e *SecretRevealedEvent) MessageIn(c *Catalog) string {
+	return c.Text("event.secret_revealed", e.ID, strings.Join(e.Messages, "; "))
 }
 
 // --- Helpers ---
 
+// eventMessages collects the non-empty messages of a list of events, in a catalog's locale.
+func eventMessages(events []Event, c *Catalog) []string {
+	var msgs []string
+	for _, evt := range events {
+		if msg := MessageIn(evt, c); msg != "" {
+			msgs = append(msgs, msg)
+		}
+	}
+	return msgs
+}
+
 // toInt safely extracts an int from various numeric types (int, int64, float64, string).
 func toInt(val any) (int, bool) {
 	switch v := val.(type) {
@@ -614,3 +1025,17 @@ func toInt(val any) (int, bool) {
 	}
 	return 0, false
 }
+
+// isTruthy interprets a flag parameter given as a bool or as text ("true", "yes").
+func isTruthy(val any) bool {
+	switch v := val.(type) {
+	case bool:
+		return v
+	case string:
+		switch strings.ToLower(v) {
+		case "true", "yes", "1":
+			return true
+		}
+	}
+	return false
+}
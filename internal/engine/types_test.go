@@ -266,6 +266,65 @@ func TestIsLoopActive_NoLoop(t *testing.T) {
 	assert.False(t, state.IsLoopActive("nonexistent"))
 }
 
+// --- Entity lifecycle ---
+func TestEntityCreatedEvent_TypeMessageApply(t *testing.T) {
+	state := NewGameState()
+	ent := NewEntity("Goblin_A", "Goblin A")
+	ent.Resources["hp"] = 7
+
+	evt := &EntityCreatedEvent{Entity: ent}
+	assert.Equal(t, "EntityCreatedEvent", evt.Type())
+	assert.Equal(t, "Goblin_A appears (7 hp)", evt.Message())
+	require.NoError(t, evt.Apply(state))
+	require.Contains(t, state.Entities, "Goblin_A")
+
+	// The state holds its own copy of the entity
+	ent.Resources["hp"] = 1
+	assert.Equal(t, 7, state.Entities["Goblin_A"].Resources["hp"])
+
+	assert.Error(t, (&EntityCreatedEvent{Entity: NewEntity("", "")}).Apply(state))
+}
+
+func TestEntityRemovedEvent_TypeMessageApply(t *testing.T) {
+	state := NewGameState()
+	for _, id := range []string{"fighter", "goblin", "wizard"} {
+		state.Entities[id] = NewEntity(id, id)
+	}
+	state.Loops["combat"] = &Loop{
+		Active:  true,
+		Actors:  []string{"fighter", "goblin", "wizard"},
+		Order:   map[string]int{"fighter": 20, "goblin": 15, "wizard": 10},
+		Current: 2,
+	}
+
+	evt := &EntityRemovedEvent{ActorID: "goblin"}
+	assert.Equal(t, "EntityRemovedEvent", evt.Type())
+	assert.Equal(t, "goblin is removed from the game", evt.Message())
+	require.NoError(t, evt.Apply(state))
+
+	assert.NotContains(t, state.Entities, "goblin")
+	loop := state.Loops["combat"]
+	assert.Equal(t, []string{"fighter", "wizard"}, loop.Actors)
+	assert.NotContains(t, loop.Order, "goblin")
+	// The wizard keeps the turn
+	assert.Equal(t, "wizard", sortedActors(loop)[loop.Current])
+
+	assert.Error(t, evt.Apply(state))
+}
+
+func TestEntityClone(t *testing.T) {
+	ent := NewEntity("goblin", "Goblin")
+	ent.Stats["dex"] = 14
+	ent.Conditions = append(ent.Conditions, "prone")
+
+	c := ent.Clone()
+	c.Stats["dex"] = 8
+	c.Conditions[0] = "hidden"
+
+	assert.Equal(t, 14, ent.Stats["dex"])
+	assert.Equal(t, []string{"prone"}, ent.Conditions)
+}
+
 // --- toInt tests ---
 func TestToInt(t *testing.T) {
 	tests := []struct {
@@ -0,0 +1,161 @@
+package engine
+
+import (
+	"slices"
+	"strings"
+)
+
+// Viewer roles used when projecting the game state.
+const (
+	RoleGM        = "gm"
+	RolePlayer    = "player"
+	RoleSpectator = "spectator"
+)
+
+// Viewer identifies who a state view is built for. A player viewer controls the entity
+// whose ID is ActorID; spectators control nothing.
+type Viewer struct {
+	Role    string
+	ActorID string
+}
+
+// ViewerFor returns the viewer of an actor: the GM for "GM", a spectator for an empty ID,
+// and a player otherwise.
+func ViewerFor(actorID string) Viewer {
+	switch {
+	case isGM(actorID):
+		return Viewer{Role: RoleGM, ActorID: actorID}
+	case actorID == "":
+		return Viewer{Role: RoleSpectator}
+	}
+	return Viewer{Role: RolePlayer, ActorID: actorID}
+}
+
+// Controls reports whether the viewer sees the entity in full.
+func (v Viewer) Controls(entityID string) bool {
+	return v.Role == RoleGM || (v.Role == RolePlayer && v.ActorID != "" && v.ActorID == entityID)
+}
+
+// ProjectState returns a copy of the state with everything the viewer must not see removed.
+//
+// The GM gets a full copy. Players see their own entity in full; of every other entity they see
+// the ID, name, types, statuses and the conditions not listed in vis.HiddenConditions. Resources
+// listed in vis.Resources are replaced by their band label in Statuses (e.g., "hp": "bloodied");
+// stats, classes, spent resources, proficiencies, inventory, actions and hooks are dropped.
+// Only metadata keys in vis.PublicMetadata, revealed secrets and loops are kept.
+func ProjectState(state *GameState, vis Visibility, viewer Viewer) *GameState {
+	view := state.Clone()
+	if viewer.Role == RoleGM {
+		return view
+	}
+
+	for id, ent := range view.Entities {
+		if !viewer.Controls(id) {
+			view.Entities[id] = redactEntity(ent, vis)
+		}
+	}
+
+	for key := range view.Metadata {
+		if !slices.Contains(vis.PublicMetadata, key) {
+			delete(view.Metadata, key)
+		}
+	}
+	view.Hooks = make(map[string]Hook)
+	view.Secrets = slices.DeleteFunc(view.Secrets, func(s Secret) bool { return !s.Revealed })
+	return view
+}
+
+// redactEntity keeps the publicly visible part of an entity.
+func redactEntity(ent *Entity, vis Visibility) *Entity {
+	r := NewEntity(ent.ID, ent.Name)
+	r.Types = ent.Types
+	r.Statuses = ent.Statuses
+	for _, c := range ent.Conditions {
+		if !hiddenCondition(vis, c) {
+			r.Conditions = append(r.Conditions, c)
+		}
+	}
+	for res, bands := range vis.Resources {
+		maxVal, ok := ent.Resources[res]
+		if !ok || maxVal <= 0 {
+			continue
+		}
+		fraction := float64(maxVal-ent.Spent[res]) / float64(maxVal)
+		if label := bandLabel(bands, fraction); label != "" {
+			r.Statuses[res] = label
+		}
+	}
+	return r
+}
+
+func hiddenCondition(vis Visibility, condition string) bool {
+	return slices.ContainsFunc(vis.HiddenConditions, func(h string) bool { return strings.EqualFold(h, condition) })
+}
+
+// ProjectEvent returns the event as the viewer may see it, or nil when the viewer must not see
+// it, by the rules of ProjectState: of the entities the viewer does not control, hidden
+// conditions, spent resources, attributes other than statuses and hooks are not shown, and
+// created entities are redacted. Metadata changes are shown for public keys only, and secrets
+// are announced without their results.
+func ProjectEvent(evt Event, vis Visibility, viewer Viewer) Event {
+	if viewer.Role == RoleGM {
+		return evt
+	}
+	switch e := Unwrap(evt).(type) {
+	case *SecretEvent:
+		return &keptSecretEvent{ID: e.ID}
+	case *EntityCreatedEvent:
+		if !viewer.Controls(e.Entity.ID) {
+			return &EntityCreatedEvent{Entity: redactEntity(e.Entity, vis)}
+		}
+	case *ConditionEvent:
+		if !viewer.Controls(e.ActorID) && hiddenCondition(vis, e.Condition) {
+			return nil
+		}
+	case *AddSpentEvent:
+		if !viewer.Controls(e.ActorID) {
+			return nil
+		}
+	case *AttributeChangedEvent:
+		if !viewer.Controls(e.ActorID) && e.Section != "statuses" {
+			return nil
+		}
+	case *MetadataChangedEvent:
+		if !slices.Contains(vis.PublicMetadata, e.Key) {
+			return nil
+		}
+	case *HookAddedEvent:
+		if !viewer.Controls(e.TargetID) {
+			return nil
+		}
+	case *HookRemovedEvent:
+		if !viewer.Controls(e.TargetID) {
+			return nil
+		}
+	case *HookFiredEvent:
+		if !viewer.Controls(e.TargetID) {
+			return nil
+		}
+	}
+	return evt
+}
+
+// keptSecretEvent is a secret as players see it: announced, without its results.
+type keptSecretEvent struct {
+	ID int `json:"id"`
+}
+
+func (e *keptSecretEvent) Type() string                 { return "SecretEvent" }
+func (e *keptSecretEvent) Apply(state *GameState) error { return nil }
+func (e *keptSecretEvent) Message() string              { return e.MessageIn(nil) }
+func (e *keptSecretEvent) MessageIn(c *Catalog) string  { return c.Text("event.secret.kept", e.ID) }
+
+// bandLabel returns the label of the first band matching the fraction, or "" if none does.
+func bandLabel(bands []ResourceBand, fraction float64) string {
+	for _, b := range bands {
+		if b.AtMost == nil || fraction <= *b.AtMost {
+			return b.Label
+		}
+	}
+	return ""
+}
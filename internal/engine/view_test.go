@@ -0,0 +1,149 @@
+package engine
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func viewTestState() *GameState {
+	state := NewGameState()
+	goblin := NewEntity("goblin", "Goblin")
+	goblin.Types = []string{"monster"}
+	goblin.Stats["ac"] = 15
+	goblin.Resources["hp"] = 8
+	goblin.Spent["hp"] = 5
+	goblin.Conditions = []string{"prone", "Invisible"}
+	goblin.Actions["scimitar"] = Action{Name: "Scimitar", AttackBonus: 4}
+	state.Entities["goblin"] = goblin
+
+	elara := NewEntity("elara", "Elara")
+	elara.Resources["hp"] = 12
+	elara.Stats["dex"] = 16
+	state.Entities["elara"] = elara
+
+	state.Loops["encounter"] = &Loop{Active: true, Actors: []string{"elara", "goblin"}, Order: map[string]int{"elara": 18, "goblin": 12}}
+	state.Metadata["pending_ask"] = map[string]any{"command": "grapple"}
+	state.Metadata["weather"] = "rain"
+	state.Secrets = []Secret{{ID: 1, Messages: []string{"roll: 17"}}, {ID: 2, Messages: []string{"roll: 3"}, Revealed: true}}
+	return state
+}
+
+func viewTestVisibility() Visibility {
+	half, zero := 0.5, 0.0
+	return Visibility{
+		Resources: map[string][]ResourceBand{
+			"hp": {{AtMost: &zero, Label: "down"}, {AtMost: &half, Label: "bloodied"}, {Label: "healthy"}},
+		},
+		HiddenConditions: []string{"invisible"},
+		PublicMetadata:   []string{"weather"},
+	}
+}
+
+func TestProjectState_GMSeesEverything(t *testing.T) {
+	state := viewTestState()
+	view := ProjectState(state, viewTestVisibility(), ViewerFor("GM"))
+
+	assert.Equal(t, state, view)
+	view.Entities["goblin"].Spent["hp"] = 0
+	assert.Equal(t, 5, state.Entities["goblin"].Spent["hp"], "the view must be a copy")
+}
+
+func TestProjectState_PlayerView(t *testing.T) {
+	state := viewTestState()
+	view := ProjectState(state, viewTestVisibility(), ViewerFor("elara"))
+
+	// Own entity is complete
+	assert.Equal(t, state.Entities["elara"], view.Entities["elara"])
+
+	goblin := view.Entities["goblin"]
+	assert.Equal(t, "Goblin", goblin.Name)
+	assert.Equal(t, []string{"monster"}, goblin.Types)
+	assert.Empty(t, goblin.Stats)
+	assert.Empty(t, goblin.Resources)
+	assert.Empty(t, goblin.Spent)
+	assert.Empty(t, goblin.Actions)
+	assert.Equal(t, []string{"prone"}, goblin.Conditions)
+	assert.Equal(t, "bloodied", goblin.Statuses["hp"])
+
+	assert.Equal(t, map[string]any{"weather": "rain"}, view.Metadata)
+	assert.Equal(t, []Secret{{ID: 2, Messages: []string{"roll: 3"}, Revealed: true}}, view.Secrets)
+	assert.True(t, view.Loops["encounter"].Active)
+
+	// The source state is untouched
+	assert.Equal(t, 15, state.Entities["goblin"].Stats["ac"])
+	assert.Len(t, state.Secrets, 2)
+}
+
+func TestProjectState_SpectatorView(t *testing.T) {
+	state := viewTestState()
+	state.Entities["goblin"].Spent["hp"] = 8
+	view := ProjectState(state, viewTestVisibility(), ViewerFor(""))
+
+	assert.Equal(t, RoleSpectator, ViewerFor("").Role)
+	assert.Equal(t, "down", view.Entities["goblin"].Statuses["hp"])
+	assert.Equal(t, "healthy", view.Entities["elara"].Statuses["hp"])
+	assert.Empty(t, view.Entities["elara"].Stats)
+}
+
+func TestProjectEvent(t *testing.T) {
+	vis := viewTestVisibility()
+	player := ViewerFor("elara")
+	hidden := &ConditionEvent{ActorID: "goblin", Condition: "invisible", Add: true}
+	narrated := &NarratedEvent{Event: &ConditionEvent{ActorID: "goblin", Condition: "prone", Add: true}, Text: "Goblin falls"}
+
+	assert.Same(t, hidden, ProjectEvent(hidden, vis, ViewerFor("GM")))
+	assert.Nil(t, ProjectEvent(hidden, vis, player))
+	assert.Nil(t, ProjectEvent(&RecordedEvent{Event: hidden}, vis, player))
+	assert.Same(t, narrated, ProjectEvent(narrated, vis, player))
+	ownHidden := &ConditionEvent{ActorID: "elara", Condition: "invisible", Add: true}
+	assert.Same(t, ownHidden, ProjectEvent(ownHidden, vis, player))
+
+	assert.Nil(t, ProjectEvent(&AddSpentEvent{ActorID: "goblin", Key: "hp", Amount: 5}, vis, player))
+	assert.NotNil(t, ProjectEvent(&AddSpentEvent{ActorID: "elara", Key: "hp", Amount: 5}, vis, player))
+	assert.Nil(t, ProjectEvent(&AttributeChangedEvent{ActorID: "goblin", Section: "stats", Key: "ac", Value: 17}, vis, player))
+	assert.NotNil(t, ProjectEvent(&AttributeChangedEvent{ActorID: "goblin", Section: "statuses", Key: "mood", Value: "angry"}, vis, player))
+	assert.Nil(t, ProjectEvent(&MetadataChangedEvent{Key: "pending_ask", Value: "x"}, vis, player))
+	assert.NotNil(t, ProjectEvent(&MetadataChangedEvent{Key: "weather", Value: "fog"}, vis, player))
+	assert.Nil(t, ProjectEvent(&HookAddedEvent{TargetID: "goblin"}, vis, player))
+
+	goblin := NewEntity("goblin", "Goblin")
+	goblin.Resources["hp"] = 7
+	created := ProjectEvent(&EntityCreatedEvent{Entity: goblin}, vis, player)
+	assert.Equal(t, NewCatalog(DefaultLocale, nil).Text("event.entity_created", "goblin"), created.Message())
+
+	secret := ProjectEvent(&SecretEvent{ID: 3}, vis, ViewerFor(""))
+	assert.Equal(t, NewCatalog(DefaultLocale, nil).Text("event.secret.kept", 3), secret.Message())
+}
+
+func TestLoadManifestLua_Visibility(t *testing.T) {
+	eval, err := NewLuaEvaluator(func(dice string) int { return 10 })
+	require.NoError(t, err)
+	defer eval.Close()
+
+	path := filepath.Join(t.TempDir(), "manifest.lua")
+	require.NoError(t, os.WriteFile(path, []byte(`
+commands = {}
+visibility = {
+    resources = {
+        hp = { { at_most = 0.5, label = "bloodied" }, { label = "healthy" } },
+    },
+    hidden_conditions = { "invisible" },
+    public_metadata = { "weather" },
+}
+`), 0644))
+
+	m, err := eval.LoadManifestLua(path)
+	require.NoError(t, err)
+	bands := m.Visibility.Resources["hp"]
+	require.Len(t, bands, 2)
+	require.NotNil(t, bands[0].AtMost)
+	assert.Equal(t, 0.5, *bands[0].AtMost)
+	assert.Equal(t, "bloodied", bands[0].Label)
+	assert.Nil(t, bands[1].AtMost)
+	assert.Equal(t, []string{"invisible"}, m.Visibility.HiddenConditions)
+	assert.Equal(t, []string{"weather"}, m.Visibility.PublicMetadata)
+}
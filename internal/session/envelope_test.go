@@ -0,0 +1,67 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/suderio/ancient-draconic/internal/engine"
+)
+
+func TestExecuteFrom_RecordsWhoIssuedEachEvent(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "manifest.lua"), `
+formatters = { ConditionEvent = function(e, names) return names[e.actor_id] .. " glows" end }
+commands = {
+    bless = { name = "bless", actor = { steps = {
+        { name = "blessed", value = "condition('blessed')" },
+        { name = "note", value = "hint('Blessed for a minute.')" },
+    } } },
+}
+`)
+	writeFile(t, filepath.Join(dir, "characters", "elara.yaml"), "id: elara\nname: Elara\n")
+	logPath := filepath.Join(dir, "log.jsonl")
+
+	s, err := NewSession([]string{dir}, logPath)
+	require.NoError(t, err)
+	defer s.Close()
+
+	events, err := s.ExecuteFrom(FrontendTelegram, "bless by: elara")
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+
+	rec, ok := engine.As[*engine.RecordedEvent](events[0])
+	require.True(t, ok)
+	assert.Equal(t, int64(1), rec.Seq)
+	assert.False(t, rec.Time.IsZero())
+	assert.Equal(t, "elara", rec.Actor)
+	assert.Equal(t, FrontendTelegram, rec.Frontend)
+	assert.Equal(t, "bless by: elara", rec.Command)
+	assert.Equal(t, "Elara glows", events[0].Message())
+	assert.IsType(t, &engine.ConditionEvent{}, engine.Unwrap(events[0]))
+
+	// Hints are not logged, so they have no envelope
+	_, ok = engine.As[*engine.RecordedEvent](events[1])
+	assert.False(t, ok)
+
+	events, err = s.Execute("bless by: elara")
+	require.NoError(t, err)
+	rec, _ = engine.As[*engine.RecordedEvent](events[0])
+	assert.Equal(t, int64(2), rec.Seq)
+	assert.Equal(t, FrontendCLI, rec.Frontend)
+
+	// An undone entry's sequence number is not issued again
+	_, err = s.Undo(1)
+	require.NoError(t, err)
+	events, err = s.Execute("bless by: elara")
+	require.NoError(t, err)
+	rec, _ = engine.As[*engine.RecordedEvent](events[0])
+	assert.Equal(t, int64(3), rec.Seq)
+
+	log, err := os.ReadFile(logPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(log), `"actor":"elara","frontend":"telegram","command":"bless by: elara","data":{"actor_id":"elara"`)
+}
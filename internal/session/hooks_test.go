@@ -47,6 +47,23 @@ func testHooksSession(t *testing.T) (*Session, string) {
 						},
 					},
 				},
+				"regenerate": {
+					Name: "regenerate",
+					Actor: engine.CommandPhase{
+						Hooks: []engine.HookDef{
+							{Name: "regen", Type: "next_turn", Value: "spend('regen', 1)", Repeats: engine.HookRepeatCount, Count: 2},
+						},
+					},
+				},
+				"burn": {
+					Name: "burn",
+					Actor: engine.CommandPhase{
+						Hooks: []engine.HookDef{
+							{Name: "burning", Type: "next_turn", Value: "spend('burn', 1)", Repeats: engine.HookRepeatAlways, StopWhen: "(actor.spent.burn or 0) >= 2"},
+							{Name: "flicker", Type: "next_turn", Value: "cancel_hook()", Repeats: engine.HookRepeatAlways},
+						},
+					},
+				},
 			},
 		},
 		state: engine.NewGameState(),
@@ -69,7 +86,7 @@ func TestHooks_NextTurn(t *testing.T) {
 	_, err := s.Execute("encounter start")
 	require.NoError(t, err)
 
-	loop := s.State().Loops["encounter_start"]
+	loop := s.state.Loops["encounter_start"]
 	loop.Actors = []string{"fighter", "wizard"}
 	loop.Order = map[string]int{"fighter": 20, "wizard": 10}
 
@@ -102,3 +119,164 @@ func TestHooks_NextTurn(t *testing.T) {
 	// The hook should be removed
 	assert.Len(t, s.State().Entities["wizard"].Hooks, 0)
 }
+
+func startHooksEncounter(t *testing.T, s *Session) {
+	t.Helper()
+	_, err := s.Execute("encounter start")
+	require.NoError(t, err)
+
+	loop := s.state.Loops["encounter_start"]
+	loop.Actors = []string{"fighter", "wizard"}
+	loop.Order = map[string]int{"fighter": 20, "wizard": 10}
+}
+
+func TestHooks_CountedRepeat(t *testing.T) {
+	s, _ := testHooksSession(t)
+	defer s.Close()
+	startHooksEncounter(t, s)
+
+	_, err := s.Execute("regenerate by: wizard")
+	require.NoError(t, err)
+	require.Contains(t, s.State().Entities["wizard"].Hooks, "regen")
+	assert.Equal(t, 2, s.State().Entities["wizard"].Hooks["regen"].Remaining)
+
+	_, err = s.Execute("turn")
+	require.NoError(t, err)
+	assert.Equal(t, 1, s.State().Entities["wizard"].Spent["regen"])
+	require.Contains(t, s.State().Entities["wizard"].Hooks, "regen")
+	assert.Equal(t, 1, s.State().Entities["wizard"].Hooks["regen"].Remaining)
+
+	_, err = s.Execute("turn")
+	require.NoError(t, err)
+	assert.Equal(t, 2, s.State().Entities["wizard"].Spent["regen"])
+	assert.NotContains(t, s.State().Entities["wizard"].Hooks, "regen")
+
+	_, err = s.Execute("turn")
+	require.NoError(t, err)
+	assert.Equal(t, 2, s.State().Entities["wizard"].Spent["regen"])
+}
+
+func TestHooks_AlwaysUntilStopAndCancel(t *testing.T) {
+	s, _ := testHooksSession(t)
+	defer s.Close()
+	startHooksEncounter(t, s)
+
+	_, err := s.Execute("burn by: fighter")
+	require.NoError(t, err)
+	require.Len(t, s.State().Entities["fighter"].Hooks, 2)
+
+	_, err = s.Execute("turn")
+	require.NoError(t, err)
+	_, err = s.Execute("turn")
+	require.NoError(t, err)
+
+	// The burning hook keeps firing; the flicker hook cancelled itself on its first run.
+	assert.Equal(t, 2, s.State().Entities["fighter"].Spent["burn"])
+	assert.Contains(t, s.State().Entities["fighter"].Hooks, "burning")
+	assert.NotContains(t, s.State().Entities["fighter"].Hooks, "flicker")
+
+	_, err = s.Execute("turn")
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, s.State().Entities["fighter"].Spent["burn"])
+	assert.NotContains(t, s.State().Entities["fighter"].Hooks, "burning")
+}
+
+// reopenHooksSession closes s and opens a new session on the same log, simulating a restart.
+func reopenHooksSession(t *testing.T, s *Session, storePath string, m *engine.Manifest) (*Session, error) {
+	t.Helper()
+	require.NoError(t, s.Close())
+
+	store, err := NewStore(storePath)
+	require.NoError(t, err)
+
+	s2 := &Session{manifest: m, state: engine.NewGameState(), store: store, eval: s.eval}
+	s2.state.Entities["fighter"] = engine.NewEntity("fighter", "Fighter")
+	s2.state.Entities["wizard"] = engine.NewEntity("wizard", "Wizard")
+	return s2, s2.rebuildState()
+}
+
+func TestHooks_SurviveRestart(t *testing.T) {
+	s, storePath := testHooksSession(t)
+	startHooksEncounter(t, s)
+
+	_, err := s.Execute("disengage by: wizard")
+	require.NoError(t, err)
+
+	s, err = reopenHooksSession(t, s, storePath, s.manifest)
+	require.NoError(t, err)
+	defer s.Close()
+
+	hook, ok := s.State().Entities["wizard"].Hooks["end_disengage"]
+	require.True(t, ok)
+	assert.Equal(t, "disengage", hook.SourceCommand)
+	assert.Equal(t, engine.PhaseActor, hook.Phase)
+	assert.Equal(t, "wizard", hook.ActorID)
+	assert.NotNil(t, hook.Value)
+
+	loop := s.state.Loops["encounter_start"]
+	loop.Actors = []string{"fighter", "wizard"}
+
+	_, err = s.Execute("turn")
+	require.NoError(t, err)
+	assert.NotContains(t, s.State().Entities["wizard"].Conditions, "disengaged")
+	assert.Empty(t, s.State().Entities["wizard"].Hooks)
+}
+
+func TestHooks_RestartFailsWhenDefinitionRemoved(t *testing.T) {
+	s, storePath := testHooksSession(t)
+	startHooksEncounter(t, s)
+
+	_, err := s.Execute("disengage by: wizard")
+	require.NoError(t, err)
+
+	m := &engine.Manifest{Commands: map[string]engine.CommandDef{}}
+	for name, cmd := range s.manifest.Commands {
+		m.Commands[name] = cmd
+	}
+	disengage := m.Commands["disengage"]
+	disengage.Actor.Hooks = nil
+	m.Commands["disengage"] = disengage
+
+	s, err = reopenHooksSession(t, s, storePath, m)
+	defer s.Close()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "end_disengage")
+}
+
+func TestExplain_TracesTheCommandAndTriggeredHooks(t *testing.T) {
+	s, _ := testHooksSession(t)
+	defer s.Close()
+	startHooksEncounter(t, s)
+
+	_, err := s.Execute("disengage by: wizard")
+	require.NoError(t, err)
+
+	logged, _ := s.store.position()
+	events, trace, err := s.Explain(FrontendCLI, "turn")
+	require.NoError(t, err)
+	assert.NotEmpty(t, events)
+	assert.Contains(t, s.State().Entities["wizard"].Conditions, "disengaged", "explained commands are dry runs")
+	count, _ := s.store.position()
+	assert.Equal(t, logged, count)
+
+	out := trace.String()
+	assert.Contains(t, out, "command turn\n")
+	assert.Contains(t, out, "step advance")
+	assert.Contains(t, out, "hook disengage.end_disengage")
+	assert.Contains(t, out, "event HookRemovedEvent")
+
+	_, trace, err = s.Explain(FrontendCLI, "disengage by: nobody")
+	require.Error(t, err)
+	assert.Equal(t, err.Error(), trace.Root.Error)
+
+	_, _, err = s.Explain(FrontendCLI, "undo steps: 1")
+	assert.EqualError(t, err, "undo cannot be explained: it changes the log or the manifest")
+	count, _ = s.store.position()
+	assert.Equal(t, logged, count)
+
+	// The trace is only recorded while explaining
+	_, err = s.Execute("turn")
+	require.NoError(t, err)
+	assert.NotContains(t, trace.String(), "step advance")
+}
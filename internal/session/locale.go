@@ -0,0 +1,78 @@
+package session
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/suderio/ancient-draconic/internal/engine"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Settings are the options of a world or campaign, read from its settings.yaml.
+type Settings struct {
+	Locale string `yaml:"locale"` // e.g. "pt-BR"; empty for English
+}
+
+// loadSettings reads the first settings.yaml found in the data directories, so a campaign's
+// settings replace the world's.
+func loadSettings(dataDirs []string) (Settings, error) {
+	var settings Settings
+	for _, dir := range dataDirs {
+		path := filepath.Join(dir, "settings.yaml")
+		data, err := os.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return settings, fmt.Errorf("failed to read settings: %w", err)
+		}
+		if err := yaml.Unmarshal(data, &settings); err != nil {
+			return settings, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		return settings, nil
+	}
+	return settings, nil
+}
+
+// loadCatalog loads the messages of the configured locale from locales/<locale>.yaml in every
+// data directory, the least specific first, so that a campaign can override single messages of
+// its world. Within a directory, a regional locale such as pt-BR is layered over its language,
+// pt; a campaign's pt.yaml still overrides its world's pt-BR.yaml.
+func loadCatalog(dataDirs []string) (*engine.Catalog, error) {
+	settings, err := loadSettings(dataDirs)
+	if err != nil {
+		return nil, err
+	}
+	locale := settings.Locale
+	if locale == "" {
+		locale = engine.DefaultLocale
+	}
+
+	names := []string{locale}
+	if lang, _, ok := strings.Cut(locale, "-"); ok {
+		names = []string{lang, locale}
+	}
+	var paths []string
+	for i := len(dataDirs) - 1; i >= 0; i-- {
+		for _, name := range names {
+			paths = append(paths, filepath.Join(dataDirs[i], "locales", name+".yaml"))
+		}
+	}
+	return engine.LoadCatalog(locale, paths...)
+}
+
+// localizeManifest loads the message catalog of the data directories and translates the
+// manifest's texts with it. The manifest keeps the catalog, and the evaluator renders tr() with it.
+func localizeManifest(dataDirs []string, m *engine.Manifest, eval *engine.LuaEvaluator) error {
+	catalog, err := loadCatalog(dataDirs)
+	if err != nil {
+		return fmt.Errorf("failed to load messages: %w", err)
+	}
+	engine.LocalizeManifest(m, catalog)
+	eval.SetCatalog(catalog)
+	return nil
+}
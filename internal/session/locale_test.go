@@ -0,0 +1,113 @@
+package session
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocale_CampaignSelectsAndOverridesMessages(t *testing.T) {
+	world := t.TempDir()
+	campaign := t.TempDir()
+	writeFile(t, filepath.Join(world, "manifest.lua"), `
+commands = {
+    bless = {
+        name = "bless", help = "Bless an ally.",
+        actor = { steps = { { name = "blessed", value = "condition('blessed')" } } },
+    },
+    shout = { name = "shout", game = { steps = { { name = "say", value = "hint(tr('shout.text', actor.name))" } } } },
+}
+`)
+	writeFile(t, filepath.Join(world, "characters", "elara.yaml"), "id: elara\nname: Elara\n")
+	writeFile(t, filepath.Join(world, "locales", "pt.yaml"), `
+event:
+  condition:
+    added: "%s agora está %s"
+command:
+  bless:
+    help: "Abençoa um aliado."
+shout:
+  text: "%s grita"
+`)
+	writeFile(t, filepath.Join(campaign, "settings.yaml"), "locale: pt-BR\n")
+	writeFile(t, filepath.Join(campaign, "locales", "pt-BR.yaml"), `shout.text: "%s berra"`)
+
+	s, err := NewSession([]string{campaign, world}, filepath.Join(campaign, "log.jsonl"))
+	require.NoError(t, err)
+	defer s.Close()
+
+	assert.Equal(t, "pt-BR", s.Catalog().Locale)
+	assert.Equal(t, "Abençoa um aliado.", s.Manifest().Commands["bless"].Help)
+
+	events, err := s.Execute("bless by: elara")
+	require.NoError(t, err)
+	assert.Equal(t, "elara agora está blessed", events[0].Message())
+
+	events, err = s.Execute("shout by: elara")
+	require.NoError(t, err)
+	assert.Equal(t, "Elara berra", events[0].Message())
+
+	// Untranslated messages stay in English
+	_, err = s.Execute("undo by: elara")
+	assert.EqualError(t, err, "unauthorized: undo can only be executed by the GM")
+
+	// Messages reload with the manifest
+	writeFile(t, filepath.Join(campaign, "locales", "pt-BR.yaml"), `shout.text: "%s sussurra"`)
+	_, err = s.Execute("reload by: GM")
+	require.NoError(t, err)
+	events, err = s.Execute("shout by: elara")
+	require.NoError(t, err)
+	assert.Equal(t, "Elara sussurra", events[0].Message())
+}
+
+func TestLocale_IsKeptPerSession(t *testing.T) {
+	world := t.TempDir()
+	writeFile(t, filepath.Join(world, "manifest.lua"), `
+commands = {
+    bless = { name = "bless", actor = { steps = { { name = "blessed", value = "condition('blessed')" } } } },
+}
+`)
+	writeFile(t, filepath.Join(world, "characters", "elara.yaml"), "id: elara\nname: Elara\n")
+	writeFile(t, filepath.Join(world, "locales", "pt.yaml"), `event.condition.added: "%s agora está %s"`)
+	portuguese, english := t.TempDir(), t.TempDir()
+	writeFile(t, filepath.Join(portuguese, "settings.yaml"), "locale: pt\n")
+
+	pt, err := NewSession([]string{portuguese, world}, filepath.Join(portuguese, "log.jsonl"))
+	require.NoError(t, err)
+	defer pt.Close()
+	en, err := NewSession([]string{english, world}, filepath.Join(english, "log.jsonl"))
+	require.NoError(t, err)
+	defer en.Close()
+
+	// Opening the English session leaves the Portuguese one in Portuguese
+	events, err := pt.Execute("bless by: elara")
+	require.NoError(t, err)
+	assert.Equal(t, "elara agora está blessed", events[0].Message())
+	events, err = en.Execute("bless by: elara")
+	require.NoError(t, err)
+	assert.Equal(t, "elara is now blessed", events[0].Message())
+}
+
+func TestLocale_CampaignLanguageOverridesWorldRegion(t *testing.T) {
+	world := t.TempDir()
+	campaign := t.TempDir()
+	writeFile(t, filepath.Join(world, "manifest.lua"), `
+commands = {
+    shout = { name = "shout", game = { steps = { { name = "say", value = "hint(tr('shout.text', actor.name))" } } } },
+}
+`)
+	writeFile(t, filepath.Join(world, "characters", "elara.yaml"), "id: elara\nname: Elara\n")
+	writeFile(t, filepath.Join(world, "settings.yaml"), "locale: pt-BR\n")
+	writeFile(t, filepath.Join(world, "locales", "pt-BR.yaml"), `shout.text: "%s brada"`)
+	writeFile(t, filepath.Join(campaign, "locales", "pt.yaml"), `shout.text: "%s berra"`)
+
+	s, err := NewSession([]string{campaign, world}, filepath.Join(campaign, "log.jsonl"))
+	require.NoError(t, err)
+	defer s.Close()
+
+	events, err := s.Execute("shout by: elara")
+	require.NoError(t, err)
+	assert.Equal(t, "Elara berra", events[0].Message())
+}
@@ -0,0 +1,59 @@
+package session
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/suderio/ancient-draconic/internal/engine"
+)
+
+func TestFormatters_NarrateEventsOfTheCommand(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "manifest.lua"), `
+formatters = {
+    ConditionEvent = function(e, names) return names[e.actor_id] .. " is now " .. e.condition end,
+    EntityRemovedEvent = function(e, names) return names[e.actor_id] .. " leaves the table" end,
+}
+commands = {
+    bless = {
+        name = "bless",
+        actor = { steps = { { name = "blessed", value = "condition('blessed')" } } },
+        format = { ConditionEvent = function(e, names) return "A soft light surrounds " .. names[e.actor_id] end },
+    },
+    curse = { name = "curse", actor = { steps = { { name = "cursed", value = "condition('cursed')" } } } },
+}
+`)
+	writeFile(t, filepath.Join(dir, "characters", "elara.yaml"), "id: elara\nname: Elara Moonwhisper\n")
+	writeFile(t, filepath.Join(dir, "characters", "bram.yaml"), "id: bram\nname: Bram\n")
+	logPath := filepath.Join(dir, "log.jsonl")
+
+	s, err := NewSession([]string{dir}, logPath)
+	require.NoError(t, err)
+
+	events, err := s.Execute("bless by: elara")
+	require.NoError(t, err)
+	assert.Equal(t, "A soft light surrounds Elara Moonwhisper", events[0].Message())
+
+	events, err = s.Execute("curse by: elara")
+	require.NoError(t, err)
+	assert.Equal(t, "Elara Moonwhisper is now cursed", events[0].Message())
+
+	// Removed entities keep their names
+	events, err = s.Execute("despawn of: bram")
+	require.NoError(t, err)
+	assert.Equal(t, "Bram leaves the table", events[0].Message())
+	s.Close()
+
+	// The log holds the events themselves
+	store, err := NewStore(logPath)
+	require.NoError(t, err)
+	defer store.Close()
+	logged, err := store.Load()
+	require.NoError(t, err)
+	require.NotEmpty(t, logged)
+	assert.IsType(t, &engine.ConditionEvent{}, logged[0])
+	assert.Equal(t, "elara is now blessed", logged[0].Message())
+}
@@ -0,0 +1,161 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/suderio/ancient-draconic/internal/engine"
+)
+
+const reducerManifest = `
+events = {
+    bleed = {
+        apply = function(state, payload)
+            local spent = state.entities[payload.who].spent.hp or 0
+            return set_attr("spent", "hp", spent + payload.amount)
+        end,
+        message = function(payload) return payload.who .. " bleeds" end,
+    },
+}
+
+commands = {
+    slash = {
+        name = "slash",
+        params = { { name = "to", type = "target" } },
+        targets = { steps = { { name = "wound", value = function()
+            return emit("bleed", { who = target.id, amount = 2 })
+        end } } },
+    },
+}
+`
+
+func TestDeclaredEvents_AreLoggedAndReplayed(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "manifest.lua"), reducerManifest)
+	writeFile(t, filepath.Join(dir, "characters", "elara.yaml"), "id: elara\nname: Elara\n")
+	writeFile(t, filepath.Join(dir, "monsters", "goblin.yaml"), "id: goblin\nname: Goblin\nresources:\n  hp: 7\n")
+	logPath := filepath.Join(dir, "log.jsonl")
+
+	s, err := NewSession([]string{dir}, logPath)
+	require.NoError(t, err)
+	events, err := s.Execute("slash by: elara to: goblin")
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, "goblin bleeds", events[0].Message())
+	_, err = s.Execute("slash by: elara to: goblin")
+	require.NoError(t, err)
+	assert.Equal(t, 4, s.State().Entities["goblin"].Spent["hp"])
+	require.NoError(t, s.Close())
+
+	log, err := os.ReadFile(logPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(log), `{"type":"bleed","seq":1,`)
+	assert.Contains(t, string(log), `"data":{"actor_id":"elara","target_id":"goblin","payload":{"amount":2,"who":"goblin"},"message":"goblin bleeds"}}`)
+
+	s, err = NewSession([]string{dir}, logPath)
+	require.NoError(t, err)
+	defer s.Close()
+	assert.Equal(t, 4, s.State().Entities["goblin"].Spent["hp"])
+
+	// Undo replays the remaining declared event through the reducer
+	_, err = s.Undo(1)
+	require.NoError(t, err)
+	assert.Equal(t, 2, s.State().Entities["goblin"].Spent["hp"])
+}
+
+func TestDeclaredEvents_FailingReducersAreNotLogged(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "manifest.lua"), `
+events = {
+    bleed = {
+        apply = function(state, payload)
+            if payload.amount > 5 then error("too much blood") end
+            return set_attr("spent", "hp", payload.amount)
+        end,
+    },
+    curse = {
+        apply = function(state, payload) return set_attr("spent", "hp", roll("1d6")) end,
+    },
+}
+
+commands = {
+    slash = {
+        name = "slash",
+        params = { { name = "to", type = "target" }, { name = "amount", type = "int" } },
+        targets = { steps = { { name = "wound", value = function()
+            return emit("bleed", { amount = tonumber(command.amount) })
+        end } } },
+    },
+    hex = {
+        name = "hex",
+        params = { { name = "to", type = "target" } },
+        targets = { steps = { { name = "doom", value = function() return emit("curse", {}) end } } },
+    },
+}
+`)
+	writeFile(t, filepath.Join(dir, "characters", "elara.yaml"), "id: elara\nname: Elara\n")
+	writeFile(t, filepath.Join(dir, "monsters", "goblin.yaml"), "id: goblin\nname: Goblin\nresources:\n  hp: 7\n")
+	logPath := filepath.Join(dir, "log.jsonl")
+
+	s, err := NewSession([]string{dir}, logPath)
+	require.NoError(t, err)
+	_, err = s.Execute("slash by: elara to: goblin amount: 9")
+	assert.ErrorContains(t, err, "too much blood")
+	_, err = s.Execute("hex by: elara to: goblin")
+	assert.ErrorContains(t, err, "roll() cannot be used in an event reducer")
+	_, err = s.Execute("slash by: elara to: goblin amount: 3")
+	require.NoError(t, err)
+	require.NoError(t, s.Close())
+
+	log, err := os.ReadFile(logPath)
+	require.NoError(t, err)
+	assert.NotContains(t, string(log), `"amount":9`)
+	assert.NotContains(t, string(log), `"type":"curse"`)
+
+	// The log still loads, with only the event that applied
+	s, err = NewSession([]string{dir}, logPath)
+	require.NoError(t, err)
+	defer s.Close()
+	assert.Equal(t, 3, s.State().Entities["goblin"].Spent["hp"])
+}
+
+func TestDeclaredEvents_DroppedByAReloadAreNotLogged(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "manifest.lua"), reducerManifest)
+	writeFile(t, filepath.Join(dir, "characters", "elara.yaml"), "id: elara\nname: Elara\n")
+	writeFile(t, filepath.Join(dir, "monsters", "goblin.yaml"), "id: goblin\nname: Goblin\nresources:\n  hp: 7\n")
+
+	s, err := NewSession([]string{dir}, filepath.Join(dir, "log.jsonl"))
+	require.NoError(t, err)
+	defer s.Close()
+	_, err = s.Execute("slash by: elara to: goblin")
+	require.NoError(t, err)
+
+	writeFile(t, filepath.Join(dir, "manifest.lua"), `commands = {}`)
+	require.NoError(t, s.Reload())
+	err = s.store.Append(&engine.DeclaredEvent{EventType: "bleed", ActorID: "elara", TargetID: "goblin"})
+	assert.ErrorContains(t, err, "event type bleed is not declared in the manifest")
+}
+
+func TestDeclaredEvents_AreKeptPerSession(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "manifest.lua"), reducerManifest)
+	writeFile(t, filepath.Join(dir, "characters", "elara.yaml"), "id: elara\nname: Elara\n")
+	writeFile(t, filepath.Join(dir, "monsters", "goblin.yaml"), "id: goblin\nname: Goblin\nresources:\n  hp: 7\n")
+	s, err := NewSession([]string{dir}, filepath.Join(dir, "log.jsonl"))
+	require.NoError(t, err)
+	defer s.Close()
+
+	// Another campaign in the same process does not declare bleed
+	other, _ := reloadTestSession(t)
+	err = other.store.Append(&engine.DeclaredEvent{EventType: "bleed", ActorID: "elara"})
+	assert.ErrorContains(t, err, "event type bleed is not declared in the manifest")
+
+	_, err = s.Execute("slash by: elara to: goblin")
+	require.NoError(t, err)
+	assert.Equal(t, 2, s.State().Entities["goblin"].Spent["hp"])
+}
@@ -0,0 +1,140 @@
+package session
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/suderio/ancient-draconic/internal/engine"
+)
+
+// Reload loads the manifest again into a fresh Lua evaluator and swaps it in, keeping the event
+// log and the game state. If the new manifest fails to load, or no longer defines a hook that is
+// active in the game, the current manifest stays in place and the error is returned.
+func (s *Session) Reload() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.reload()
+}
+
+func (s *Session) reload() error {
+	eval, err := newEvaluator(s.dataDirs, s.rollFunc)
+	if err != nil {
+		return fmt.Errorf("failed to create evaluator: %w", err)
+	}
+
+	m, sources, err := findAndLoadManifest(s.dataDirs, eval)
+	if err != nil {
+		// Keep watching the modules the broken manifest got to, so that fixing them reloads
+		for _, src := range sources {
+			if !slices.Contains(s.sources, src) {
+				s.sources = append(s.sources, src)
+			}
+		}
+		eval.Close()
+		return fmt.Errorf("failed to reload manifest: %w", err)
+	}
+
+	if err := localizeManifest(s.dataDirs, m, eval); err != nil {
+		eval.Close()
+		return fmt.Errorf("failed to reload manifest: %w", err)
+	}
+
+	// Active hooks hold closures of the old evaluator: bind a copy of the state to the new
+	// manifest, so that a failure leaves the current state untouched.
+	state := s.state.Clone()
+	engine.BindEvents(state, m, eval)
+	if err := engine.BindHooks(state, m); err != nil {
+		eval.Close()
+		return fmt.Errorf("failed to reload manifest: %w", err)
+	}
+
+	old := s.eval
+	s.manifest, s.eval, s.state, s.sources = m, eval, state, sources
+	if old != nil {
+		old.Close()
+	}
+
+	// Loading the log again must not warn about the manifest the GM just reloaded
+	if err := s.recordManifest(); err != nil {
+		fmt.Printf("Warning: failed to record the reloaded manifest: %v\n", err)
+	}
+	return nil
+}
+
+// Watch polls the manifest and its required modules every interval and reloads the manifest
+// when any of them changes. notify is called with the result of every automatic reload (nil on
+// success). The returned function stops watching.
+func (s *Session) Watch(interval time.Duration, notify func(error)) (stop func()) {
+	s.mu.Lock()
+	seen := modTimes(s.watchedFiles())
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	ticker := time.NewTicker(interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+
+			s.mu.Lock()
+			files := s.watchedFiles()
+			s.mu.Unlock()
+
+			changed := false
+			for path, modTime := range modTimes(files) {
+				prev, known := seen[path]
+				if !known {
+					// A module required lazily, from inside a formula, since the last check
+					seen[path] = modTime
+				} else if !modTime.Equal(prev) {
+					changed = true
+				}
+			}
+			if !changed {
+				continue
+			}
+
+			s.mu.Lock()
+			err := s.reload()
+			// After a failed reload, wait for the next change rather than retrying every tick
+			seen = modTimes(s.watchedFiles())
+			s.mu.Unlock()
+
+			if notify != nil {
+				notify(err)
+			}
+		}
+	}()
+	return func() { close(done) }
+}
+
+// watchedFiles returns the manifest and module files Watch checks for changes.
+// The caller must hold s.mu.
+func (s *Session) watchedFiles() []string {
+	files := slices.Clone(s.sources)
+	for _, path := range s.eval.ModuleFiles() {
+		if !slices.Contains(files, path) {
+			files = append(files, path)
+		}
+	}
+	return files
+}
+
+// modTimes returns the modification time of each file (zero for missing files).
+func modTimes(paths []string) map[string]time.Time {
+	times := make(map[string]time.Time, len(paths))
+	for _, path := range paths {
+		var modTime time.Time
+		if info, err := os.Stat(path); err == nil {
+			modTime = info.ModTime()
+		}
+		times[path] = modTime
+	}
+	return times
+}
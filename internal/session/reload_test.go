@@ -0,0 +1,172 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const reloadManifest = `
+local words = require("words")
+
+commands = {
+    bless = {
+        name = "bless",
+        actor = { hooks = { { name = "blessed", type = "next_turn", value = "nil", repeats = "always" } } },
+    },
+    shout = {
+        name = "shout",
+        game = { steps = { { name = "say", value = function() return hint(words.word) end } } },
+    },
+}
+`
+
+// reloadTestSession creates a session on a world directory with a manifest, a module and a character.
+func reloadTestSession(t *testing.T) (*Session, string) {
+	t.Helper()
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "manifest.lua"), reloadManifest)
+	writeFile(t, filepath.Join(dir, "words.lua"), `return { word = "hello" }`)
+	writeFile(t, filepath.Join(dir, "characters", "elara.yaml"), "id: elara\nname: Elara\nresources:\n  hp: 12\n")
+
+	s, err := NewSession([]string{dir}, filepath.Join(dir, "log.jsonl"))
+	require.NoError(t, err)
+	t.Cleanup(func() { s.Close() })
+	return s, dir
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+func shout(t *testing.T, s *Session) string {
+	t.Helper()
+	events, err := s.Execute("shout by: elara")
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	return events[0].Message()
+}
+
+func TestReload_KeepsStateAndSwapsManifest(t *testing.T) {
+	s, dir := reloadTestSession(t)
+	_, err := s.Execute("bless by: elara")
+	require.NoError(t, err)
+	assert.Equal(t, "hello", shout(t, s))
+
+	writeFile(t, filepath.Join(dir, "words.lua"), `return { word = "goodbye" }`)
+	require.NoError(t, s.Reload())
+
+	assert.Equal(t, "goodbye", shout(t, s))
+	hook, ok := s.State().Entities["elara"].Hooks["blessed"]
+	require.True(t, ok, "active hooks survive a reload")
+	assert.NotNil(t, hook.Value)
+}
+
+func TestReload_KeepsOldManifestOnError(t *testing.T) {
+	s, dir := reloadTestSession(t)
+	_, err := s.Execute("bless by: elara")
+	require.NoError(t, err)
+
+	// A syntax error in a module
+	writeFile(t, filepath.Join(dir, "words.lua"), `return { word = }`)
+	err = s.Reload()
+	assert.ErrorContains(t, err, "words.lua")
+	assert.Equal(t, "hello", shout(t, s))
+
+	// A manifest that drops the definition of an active hook
+	writeFile(t, filepath.Join(dir, "words.lua"), `return { word = "hello" }`)
+	writeFile(t, filepath.Join(dir, "manifest.lua"), `commands = {}`)
+	err = s.Reload()
+	assert.ErrorContains(t, err, "hook blessed of command bless")
+	assert.Contains(t, s.Manifest().Commands, "shout")
+	assert.Contains(t, s.State().Entities["elara"].Hooks, "blessed")
+}
+
+func TestState_IsACopySafeToReadDuringCommands(t *testing.T) {
+	s, _ := reloadTestSession(t)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for range 20 {
+			_, _ = s.Execute("bless by: elara")
+			_ = s.Reload()
+		}
+	}()
+	for {
+		select {
+		case <-done:
+			state := s.State()
+			state.Entities["elara"].Name = "Changed"
+			assert.Equal(t, "Elara", s.State().Entities["elara"].Name)
+			return
+		default:
+			_ = len(s.State().Entities["elara"].Hooks)
+		}
+	}
+}
+
+func TestReload_Builtin(t *testing.T) {
+	s, dir := reloadTestSession(t)
+	writeFile(t, filepath.Join(dir, "words.lua"), `return { word = "goodbye" }`)
+
+	_, err := s.Execute("reload by: elara")
+	assert.ErrorContains(t, err, "unauthorized")
+
+	events, err := s.Execute("reload")
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, "Manifest reloaded.", events[0].Message())
+	assert.Equal(t, "goodbye", shout(t, s))
+}
+
+func TestWatch_ReloadsOnChange(t *testing.T) {
+	s, dir := reloadTestSession(t)
+	results := make(chan error, 4)
+	stop := s.Watch(10*time.Millisecond, func(err error) { results <- err })
+	defer stop()
+
+	path := filepath.Join(dir, "words.lua")
+	writeFile(t, path, `return { word = "goodbye" }`)
+	later := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(path, later, later))
+
+	select {
+	case err := <-results:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the module change was not picked up")
+	}
+	assert.Equal(t, "goodbye", shout(t, s))
+}
+
+func TestOverlays_CampaignPatchesWorldManifest(t *testing.T) {
+	world := t.TempDir()
+	campaign := t.TempDir()
+	writeFile(t, filepath.Join(world, "manifest.lua"), reloadManifest)
+	writeFile(t, filepath.Join(world, "words.lua"), `return { word = "hello" }`)
+	writeFile(t, filepath.Join(world, "characters", "elara.yaml"), "id: elara\nname: Elara\n")
+	overlay := filepath.Join(campaign, "overlays", "house_rules.lua")
+	writeFile(t, overlay, `commands.shout.help = "Shout louder."`)
+
+	s, err := NewSession([]string{campaign, world}, filepath.Join(campaign, "log.jsonl"))
+	require.NoError(t, err)
+	defer s.Close()
+
+	assert.Equal(t, "house_rules", s.Manifest().Commands["shout"].Layer)
+	assert.Empty(t, s.Manifest().Commands["bless"].Layer)
+	assert.Contains(t, s.sources, overlay)
+	assert.Equal(t, "hello", shout(t, s))
+
+	// Overlays reload with the manifest
+	writeFile(t, overlay, `local base = commands.shout.game.steps[1].value
+commands.shout.game.steps[1].value = function() local h = base(); h.message = h.message .. "!"; return h end`)
+	_, err = s.Execute("reload by: GM")
+	require.NoError(t, err)
+	assert.Equal(t, "hello!", shout(t, s))
+}
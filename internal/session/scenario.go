@@ -0,0 +1,320 @@
+package session
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/suderio/ancient-draconic/internal/engine"
+)
+
+// Scenario is a rules regression test written in YAML: entities to start from, the dice results
+// to use and a list of commands, each with the outcome it must have.
+type Scenario struct {
+	Name     string           `yaml:"name"`
+	Dice     []int            `yaml:"dice"`     // totals returned by successive roll() calls
+	Entities []*engine.Entity `yaml:"entities"` // the only entities in the game; extends is resolved
+	Steps    []ScenarioStep   `yaml:"steps"`
+
+	file string
+}
+
+// ScenarioStep is one command of a scenario and its expected outcome. Unset fields are not checked.
+type ScenarioStep struct {
+	Command  string                       `yaml:"command"`
+	Dice     []int                        `yaml:"dice"`     // appended to the remaining dice before the command
+	Error    string                       `yaml:"error"`    // the command must fail with an error containing this
+	Events   []string                     `yaml:"events"`   // event types that must occur, in this order
+	Messages []string                     `yaml:"messages"` // texts each contained in some event's message
+	Entities map[string]EntityExpectation `yaml:"entities"` // entity state after the command, by ID
+	Loops    map[string]LoopExpectation   `yaml:"loops"`    // loop state after the command, by name
+}
+
+// EntityExpectation checks part of an entity. Map fields only check the keys they list.
+type EntityExpectation struct {
+	Absent     bool              `yaml:"absent"` // the entity must not exist
+	Stats      map[string]int    `yaml:"stats"`
+	Resources  map[string]int    `yaml:"resources"`
+	Spent      map[string]int    `yaml:"spent"`
+	Statuses   map[string]string `yaml:"statuses"`
+	Inventory  map[string]int    `yaml:"inventory"`
+	Conditions []string          `yaml:"conditions"` // the exact set of conditions, in any order
+}
+
+// LoopExpectation checks a loop. A zero Round is not checked.
+type LoopExpectation struct {
+	Active *bool `yaml:"active"`
+	Round  int   `yaml:"round"`
+}
+
+// ScenarioResult is the outcome of running a scenario. A scenario stops at its first failing step.
+type ScenarioResult struct {
+	Name     string
+	File     string
+	Failures []string
+}
+
+// Passed reports whether every step of the scenario had its expected outcome.
+func (r ScenarioResult) Passed() bool {
+	return len(r.Failures) == 0
+}
+
+// LoadScenario reads a scenario file. Scenarios without a name are named after the file.
+func LoadScenario(path string) (*Scenario, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open scenario %s: %w", path, err)
+	}
+	defer f.Close()
+
+	var sc Scenario
+	if err := yaml.NewDecoder(f).Decode(&sc); err != nil {
+		return nil, fmt.Errorf("failed to decode scenario %s: %w", path, err)
+	}
+	if sc.Name == "" {
+		sc.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	sc.file = path
+	return &sc, nil
+}
+
+// RunScenarios runs every *.yaml scenario in dir, in file name order, against the manifest found
+// in dataDirs. Files that cannot be read are reported as failed scenarios.
+func RunScenarios(dataDirs []string, dir string) ([]ScenarioResult, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.yaml"))
+	if err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no scenario files (*.yaml) in %s", dir)
+	}
+
+	var results []ScenarioResult
+	for _, path := range paths {
+		sc, err := LoadScenario(path)
+		if err != nil {
+			results = append(results, ScenarioResult{Name: filepath.Base(path), File: path, Failures: []string{err.Error()}})
+			continue
+		}
+		results = append(results, RunScenario(dataDirs, sc))
+	}
+	return results, nil
+}
+
+// RunScenario plays a scenario in a fresh session on a temporary event log. Only the scenario's
+// entities exist in the game; entity files in dataDirs are used as extends templates.
+func RunScenario(dataDirs []string, sc *Scenario) ScenarioResult {
+	result := ScenarioResult{Name: sc.Name, File: sc.file}
+	fail := func(format string, args ...any) ScenarioResult {
+		result.Failures = append(result.Failures, fmt.Sprintf(format, args...))
+		return result
+	}
+
+	dice := &scriptedDice{results: slices.Clone(sc.Dice)}
+	s, cleanup, err := newScenarioSession(dataDirs, dice.roll)
+	if err != nil {
+		return fail("%v", err)
+	}
+	defer cleanup()
+
+	for _, e := range sc.Entities {
+		ent, err := engine.ResolveExtends(e, s.lookupTemplate)
+		if err != nil {
+			return fail("setup: %v", err)
+		}
+		if ent.ID == "" {
+			return fail("setup: an entity has no id")
+		}
+		// Logged rather than set, so that undo in a scenario replays the setup too
+		if _, err := s.applyAndPersist(&engine.EntityCreatedEvent{Entity: ent}, engine.Envelope{Frontend: FrontendCLI}); err != nil {
+			return fail("setup: %v", err)
+		}
+	}
+
+	for i, step := range sc.Steps {
+		dice.results = append(dice.results, step.Dice...)
+		events, err := s.Execute(step.Command)
+
+		prefix := fmt.Sprintf("step %d (%s)", i+1, step.Command)
+		if len(dice.missing) > 0 {
+			return fail("%s: ran out of dice at roll(%s)", prefix, strings.Join(dice.missing, "), roll("))
+		}
+		for _, problem := range checkStep(step, s.state, events, err) {
+			result.Failures = append(result.Failures, prefix+": "+problem)
+		}
+		if !result.Passed() {
+			return result
+		}
+	}
+	return result
+}
+
+// newScenarioSession creates a session that ignores the entity files of dataDirs, backed by an
+// event log in a temporary directory that cleanup removes.
+func newScenarioSession(dataDirs []string, rollFunc engine.RollFunc) (*Session, func(), error) {
+	eval, err := newEvaluator(dataDirs, rollFunc)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create evaluator: %w", err)
+	}
+	m, sources, err := findAndLoadManifest(dataDirs, eval)
+	if err != nil {
+		eval.Close()
+		return nil, nil, fmt.Errorf("failed to load manifest: %w", err)
+	}
+	if err := localizeManifest(dataDirs, m, eval); err != nil {
+		eval.Close()
+		return nil, nil, err
+	}
+
+	dir, err := os.MkdirTemp("", "draconic-scenario-")
+	if err != nil {
+		eval.Close()
+		return nil, nil, err
+	}
+	store, err := NewStore(filepath.Join(dir, "log.jsonl"))
+	if err != nil {
+		eval.Close()
+		os.RemoveAll(dir)
+		return nil, nil, fmt.Errorf("failed to open event store: %w", err)
+	}
+
+	s := &Session{
+		manifest: m,
+		state:    engine.NewGameState(),
+		store:    store,
+		eval:     eval,
+		dataDirs: dataDirs,
+		rollFunc: rollFunc,
+		sources:  sources,
+
+		noEntityFiles: true,
+	}
+	store.declares = s.declares
+	engine.BindEvents(s.state, m, eval)
+	cleanup := func() {
+		s.Close()
+		s.eval.Close()
+		os.RemoveAll(dir)
+	}
+	return s, cleanup, nil
+}
+
+// scriptedDice returns the scenario's dice results in order, recording the rolls made after they
+// ran out.
+type scriptedDice struct {
+	results []int
+	missing []string
+}
+
+func (d *scriptedDice) roll(dice string) int {
+	if len(d.results) == 0 {
+		d.missing = append(d.missing, dice)
+		return 1
+	}
+	r := d.results[0]
+	d.results = d.results[1:]
+	return r
+}
+
+// checkStep compares the outcome of a step's command with its expectations.
+func checkStep(step ScenarioStep, state *engine.GameState, events []engine.Event, err error) []string {
+	switch {
+	case err != nil && step.Error == "":
+		return []string{fmt.Sprintf("unexpected error: %v", err)}
+	case err != nil && !strings.Contains(err.Error(), step.Error):
+		return []string{fmt.Sprintf("expected an error containing %q, got: %v", step.Error, err)}
+	case err == nil && step.Error != "":
+		return []string{fmt.Sprintf("expected an error containing %q, but the command succeeded", step.Error)}
+	}
+
+	var problems []string
+	var types, messages []string
+	for _, evt := range events {
+		types = append(types, evt.Type())
+		messages = append(messages, evt.Message())
+	}
+	if missing := missingSubsequence(types, step.Events); missing != "" {
+		problems = append(problems, fmt.Sprintf("expected event %s (in order %s), got %s", missing, strings.Join(step.Events, ", "), strings.Join(types, ", ")))
+	}
+	for _, text := range step.Messages {
+		if !slices.ContainsFunc(messages, func(m string) bool { return strings.Contains(m, text) }) {
+			problems = append(problems, fmt.Sprintf("no event message contains %q; messages: %q", text, messages))
+		}
+	}
+
+	for _, id := range slices.Sorted(maps.Keys(step.Entities)) {
+		problems = append(problems, checkEntity(id, state.Entities[id], step.Entities[id])...)
+	}
+	for _, name := range slices.Sorted(maps.Keys(step.Loops)) {
+		want, loop := step.Loops[name], state.Loops[name]
+		active := loop != nil && loop.Active
+		if want.Active != nil && *want.Active != active {
+			problems = append(problems, fmt.Sprintf("loop %s: expected active %t, got %t", name, *want.Active, active))
+		}
+		if want.Round != 0 && (loop == nil || loop.Round != want.Round) {
+			got := 0
+			if loop != nil {
+				got = loop.Round
+			}
+			problems = append(problems, fmt.Sprintf("loop %s: expected round %d, got %d", name, want.Round, got))
+		}
+	}
+	return problems
+}
+
+// missingSubsequence returns the first expected item that does not appear, in order, in got.
+func missingSubsequence(got, want []string) string {
+	i := 0
+	for _, w := range want {
+		for i < len(got) && got[i] != w {
+			i++
+		}
+		if i == len(got) {
+			return w
+		}
+		i++
+	}
+	return ""
+}
+
+func checkEntity(id string, ent *engine.Entity, want EntityExpectation) []string {
+	if want.Absent {
+		if ent != nil {
+			return []string{fmt.Sprintf("entity %s: expected to be absent", id)}
+		}
+		return nil
+	}
+	if ent == nil {
+		return []string{fmt.Sprintf("entity %s: does not exist", id)}
+	}
+
+	var problems []string
+	problems = append(problems, compareFields(id, "stats", ent.Stats, want.Stats)...)
+	problems = append(problems, compareFields(id, "resources", ent.Resources, want.Resources)...)
+	problems = append(problems, compareFields(id, "spent", ent.Spent, want.Spent)...)
+	problems = append(problems, compareFields(id, "statuses", ent.Statuses, want.Statuses)...)
+	problems = append(problems, compareFields(id, "inventory", ent.Inventory, want.Inventory)...)
+	if want.Conditions != nil {
+		got, expected := slices.Sorted(slices.Values(ent.Conditions)), slices.Sorted(slices.Values(want.Conditions))
+		if !slices.Equal(got, expected) {
+			problems = append(problems, fmt.Sprintf("entity %s: expected conditions %v, got %v", id, expected, got))
+		}
+	}
+	return problems
+}
+
+// compareFields checks the listed keys of an entity map; missing keys read as the zero value.
+func compareFields[V comparable](id, field string, got, want map[string]V) []string {
+	var problems []string
+	for _, key := range slices.Sorted(maps.Keys(want)) {
+		if got[key] != want[key] {
+			problems = append(problems, fmt.Sprintf("entity %s: expected %s.%s = %v, got %v", id, field, key, want[key], got[key]))
+		}
+	}
+	return problems
+}
@@ -0,0 +1,117 @@
+package session
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const scenarioManifest = `
+commands = {
+    strike = {
+        name = "strike",
+        params = { { name = "to", type = "target" } },
+        targets = {
+            steps = {
+                { name = "damage", value = function() return set_attr("spent", "hp", (target.spent.hp or 0) + roll("1d8")) end },
+            },
+        },
+    },
+}
+`
+
+// runScenarioFile runs one scenario against the strike manifest, with a goblin template on disk.
+func runScenarioFile(t *testing.T, scenario string) ScenarioResult {
+	t.Helper()
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "manifest.lua"), scenarioManifest)
+	writeFile(t, filepath.Join(dir, "monsters", "goblin.yaml"), "id: goblin\nname: Goblin\nresources:\n  hp: 7\n")
+	writeFile(t, filepath.Join(dir, "tests", "scenario.yaml"), scenario)
+
+	results, err := RunScenarios([]string{dir}, filepath.Join(dir, "tests"))
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	return results[0]
+}
+
+func TestRunScenario_Passes(t *testing.T) {
+	result := runScenarioFile(t, `
+entities:
+  - { id: hero, name: Hero }
+  - { id: grunt, extends: goblin }
+dice: [3]
+steps:
+  - command: "strike by: hero to: grunt"
+    events: [AttributeChangedEvent]
+    messages: ["grunt"]
+    entities:
+      grunt: { resources: { hp: 7 }, spent: { hp: 3 }, conditions: [] }
+      goblin: { absent: true }
+  - command: "strike by: hero to: grunt"
+    dice: [2]
+    entities:
+      grunt: { spent: { hp: 5 } }
+  - command: "undo by: GM"
+    entities:
+      grunt: { spent: { hp: 3 } }
+      hero: {}
+`)
+	assert.Equal(t, "scenario", result.Name)
+	assert.True(t, result.Passed(), "%v", result.Failures)
+}
+
+func TestRunScenario_ReportsTheFirstFailingStep(t *testing.T) {
+	result := runScenarioFile(t, `
+name: wrong damage
+entities:
+  - { id: hero, name: Hero }
+  - { id: grunt, extends: goblin }
+dice: [3, 4]
+steps:
+  - command: "strike by: hero to: grunt"
+    events: [ConditionEvent]
+    entities:
+      grunt: { spent: { hp: 4 } }
+  - command: "strike by: hero to: grunt"
+`)
+	assert.Equal(t, "wrong damage", result.Name)
+	assert.Equal(t, []string{
+		"step 1 (strike by: hero to: grunt): expected event ConditionEvent (in order ConditionEvent), got AttributeChangedEvent",
+		"step 1 (strike by: hero to: grunt): entity grunt: expected spent.hp = 4, got 3",
+	}, result.Failures)
+}
+
+func TestRunScenario_ChecksErrors(t *testing.T) {
+	result := runScenarioFile(t, `
+entities:
+  - { id: hero, name: Hero }
+steps:
+  - command: "strike by: hero to: nobody"
+    error: "nobody"
+  - command: "strike by: hero to: nobody"
+`)
+	require.Len(t, result.Failures, 1)
+	assert.Contains(t, result.Failures[0], "step 2 (strike by: hero to: nobody): unexpected error:")
+}
+
+func TestRunScenario_FailsWhenDiceRunOut(t *testing.T) {
+	result := runScenarioFile(t, `
+entities:
+  - { id: hero, name: Hero }
+  - { id: grunt, extends: goblin }
+steps:
+  - command: "strike by: hero to: grunt"
+`)
+	assert.Equal(t, []string{"step 1 (strike by: hero to: grunt): ran out of dice at roll(1d8)"}, result.Failures)
+}
+
+func TestRunScenarios_BundledWorld(t *testing.T) {
+	world := "../../world/dnd5e"
+	results, err := RunScenarios([]string{world}, filepath.Join(world, "tests"))
+	require.NoError(t, err)
+	for _, r := range results {
+		assert.True(t, r.Passed(), "%s: %v", r.Name, r.Failures)
+	}
+}
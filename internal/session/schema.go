@@ -0,0 +1,208 @@
+package session
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// SchemaVersion is the version of the event format the engine writes. Changing how a logged
+// event is encoded means bumping it and registering the migration from the previous version
+// with RegisterMigration.
+const SchemaVersion = 1
+
+// legacySchema is the schema of logs written before logs had headers.
+const legacySchema = 1
+
+// logHeaderType marks the header line of an event log.
+const logHeaderType = "LogHeader"
+
+// LogHeader is the first line of an event log: the event schema the log is written in and the
+// fingerprint of the manifest the log was started with. Seq is the highest sequence number
+// issued before the log was last rewritten, so that entries dropped by an undo keep theirs.
+type LogHeader struct {
+	Schema   int    `json:"schema"`
+	Manifest string `json:"manifest,omitempty"`
+	Seq      int64  `json:"seq,omitempty"`
+}
+
+// Migration upgrades the entries of a log from schema From to schema From+1. Upgrade is called
+// for every entry, also for the events wrapped in secrets, and returns the entries replacing it:
+// none to drop it, several to split it into new event types.
+type Migration struct {
+	From    int
+	Upgrade func(EventWrapper) ([]EventWrapper, error)
+}
+
+// migrations is the chain of schema upgrades, applied in order to logs older than SchemaVersion.
+var migrations []Migration
+
+// RegisterMigration adds the upgrade of log entries from schema from to schema from+1 to the
+// chain run on older logs; see Migration. Registering a migration from the same schema again
+// replaces it. It must be called before sessions are created, e.g., from an init function.
+func RegisterMigration(from int, upgrade func(EventWrapper) ([]EventWrapper, error)) {
+	m := Migration{From: from, Upgrade: upgrade}
+	for i := range migrations {
+		if migrations[i].From == from {
+			migrations[i] = m
+			return
+		}
+	}
+	migrations = append(migrations, m)
+}
+
+// migrateEntries upgrades entries written in schema from to schema to.
+func migrateEntries(entries []EventWrapper, from, to int) ([]EventWrapper, error) {
+	for version := from; version < to; version++ {
+		m, ok := findMigration(version)
+		if !ok {
+			return nil, fmt.Errorf("no migration from event schema %d to %d", version, version+1)
+		}
+		var upgraded []EventWrapper
+		for _, entry := range entries {
+			out, err := upgradeEntry(m, entry)
+			if err != nil {
+				return nil, fmt.Errorf("schema %d to %d: %s: %w", version, version+1, entry.Type, err)
+			}
+			upgraded = append(upgraded, out...)
+		}
+		entries = upgraded
+	}
+	return entries, nil
+}
+
+func findMigration(from int) (Migration, bool) {
+	for _, m := range migrations {
+		if m.From == from {
+			return m, true
+		}
+	}
+	return Migration{}, false
+}
+
+// upgradeEntry applies a migration to an entry and, for secrets, to the events they wrap.
+func upgradeEntry(m Migration, entry EventWrapper) ([]EventWrapper, error) {
+	if entry.Type == "SecretEvent" {
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(entry.Data, &fields); err != nil {
+			return nil, err
+		}
+		var inner []EventWrapper
+		if err := json.Unmarshal(fields["events"], &inner); err != nil {
+			return nil, err
+		}
+		var upgraded []EventWrapper
+		for _, e := range inner {
+			out, err := upgradeEntry(m, e)
+			if err != nil {
+				return nil, err
+			}
+			upgraded = append(upgraded, out...)
+		}
+		data, err := json.Marshal(upgraded)
+		if err != nil {
+			return nil, err
+		}
+		fields["events"] = data
+		if entry.Data, err = json.Marshal(fields); err != nil {
+			return nil, err
+		}
+	}
+	return m.Upgrade(entry)
+}
+
+// upgradeLog brings the event log up to date before it is replayed: it migrates a log written
+// in an older schema, keeping the original next to it, and records the manifest fingerprint in
+// logs that lack one. It returns warnings for the GM, including one when the log was started
+// with a different manifest, whose fingerprint then replaces the recorded one so that the GM is
+// warned once per manifest change.
+func (s *Session) upgradeLog() ([]string, error) {
+	fingerprint, err := manifestFingerprint(s.sources)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fingerprint manifest: %w", err)
+	}
+
+	header := s.store.header
+	if header.Schema > SchemaVersion {
+		return nil, fmt.Errorf("event log schema %d is newer than this engine supports (%d)", header.Schema, SchemaVersion)
+	}
+	entries, err := s.store.entries()
+	if err != nil {
+		return nil, err
+	}
+
+	var warnings []string
+	if header.Schema < SchemaVersion {
+		entries, err = migrateEntries(entries, header.Schema, SchemaVersion)
+		if err != nil {
+			return nil, fmt.Errorf("failed to migrate event log: %w", err)
+		}
+		backup := fmt.Sprintf("%s.schema%d", s.store.file.Name(), header.Schema)
+		if err := copyFile(s.store.file.Name(), backup); err != nil {
+			return nil, fmt.Errorf("failed to back up event log: %w", err)
+		}
+		warnings = append(warnings, fmt.Sprintf("event log upgraded from schema %d to %d; the original is kept in %s", header.Schema, SchemaVersion, backup))
+		header.Schema = SchemaVersion
+	}
+
+	switch {
+	case header.Manifest == "" && len(entries) > 0:
+		warnings = append(warnings, "event log does not record the manifest it was started with; recording the current one")
+		header.Manifest = fingerprint
+	case header.Manifest == "":
+		header.Manifest = fingerprint
+	case header.Manifest != fingerprint:
+		warnings = append(warnings, fmt.Sprintf("event log was started with a different manifest (%s, now %s); replaying it may fail or differ; recording the current one", header.Manifest, fingerprint))
+		header.Manifest = fingerprint
+	}
+
+	if header != s.store.header {
+		if err := s.store.rewriteEntries(header, entries); err != nil {
+			return nil, fmt.Errorf("failed to rewrite event log: %w", err)
+		}
+	}
+	return warnings, nil
+}
+
+// recordManifest records the fingerprint of the loaded manifest in the log header, for a
+// manifest the GM reloaded on purpose.
+func (s *Session) recordManifest() error {
+	fingerprint, err := manifestFingerprint(s.sources)
+	if err != nil {
+		return err
+	}
+	if s.store == nil || s.store.header.Manifest == fingerprint {
+		return nil
+	}
+	header := s.store.header
+	header.Manifest = fingerprint
+	return s.store.setHeader(header)
+}
+
+// manifestFingerprint hashes the manifest and the modules it requires, in load order.
+func manifestFingerprint(sources []string) (string, error) {
+	h := sha256.New()
+	for _, src := range sources {
+		f, err := os.Open(src)
+		if err != nil {
+			return "", err
+		}
+		_, err = io.Copy(h, f)
+		f.Close()
+		if err != nil {
+			return "", err
+		}
+	}
+	return "sha256:" + hex.EncodeToString(h.Sum(nil))[:16], nil
+}
+
+func copyFile(src, dst string) error {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(dst, data, 0644)
+}
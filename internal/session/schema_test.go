@@ -0,0 +1,140 @@
+package session
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// logLines returns the lines of an event log file.
+func logLines(t *testing.T, path string) []string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return strings.Split(strings.TrimSpace(string(data)), "\n")
+}
+
+func TestLogHeader_RecordsSchemaAndManifest(t *testing.T) {
+	s, dir := reloadTestSession(t)
+	logPath := filepath.Join(dir, "log.jsonl")
+	_, err := s.Execute("bless by: elara")
+	require.NoError(t, err)
+
+	lines := logLines(t, logPath)
+	assert.Equal(t, `{"type":"LogHeader","data":{"schema":1,"manifest":"`+mustFingerprint(t, s)+`"}}`, lines[0])
+
+	// The header is not an event, and undo keeps it, with the highest sequence number issued
+	count, err := s.store.EventCount()
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+	_, err = s.Undo(1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{`{"type":"LogHeader","data":{"schema":1,"manifest":"` + mustFingerprint(t, s) + `","seq":1}}`},
+		logLines(t, logPath))
+}
+
+func TestUpgradeLog_Warnings(t *testing.T) {
+	s, dir := reloadTestSession(t)
+	logPath := filepath.Join(dir, "log.jsonl")
+	_, err := s.Execute("bless by: elara")
+	require.NoError(t, err)
+
+	warnings, err := s.upgradeLog()
+	require.NoError(t, err)
+	assert.Empty(t, warnings)
+
+	writeFile(t, filepath.Join(dir, "words.lua"), `return { word = "goodbye" }`)
+	warnings, err = s.upgradeLog()
+	require.NoError(t, err)
+	require.Len(t, warnings, 1)
+	assert.Contains(t, warnings[0], "event log was started with a different manifest")
+
+	// The new manifest is recorded: the GM is only warned once
+	warnings, err = s.upgradeLog()
+	require.NoError(t, err)
+	assert.Empty(t, warnings)
+
+	// A manifest reloaded on purpose is recorded without a warning
+	writeFile(t, filepath.Join(dir, "words.lua"), `return { word = "again" }`)
+	require.NoError(t, s.Reload())
+	assert.Equal(t, mustFingerprint(t, s), s.store.header.Manifest)
+	warnings, err = s.upgradeLog()
+	require.NoError(t, err)
+	assert.Empty(t, warnings)
+
+	// A log written before headers existed gets one, with the current manifest
+	require.NoError(t, s.Close())
+	lines := logLines(t, logPath)
+	writeFile(t, logPath, strings.Join(lines[1:], "\n")+"\n")
+	s, err = NewSession([]string{dir}, logPath)
+	require.NoError(t, err)
+	defer s.Close()
+	assert.Contains(t, s.State().Entities["elara"].Hooks, "blessed")
+	assert.Equal(t, LogHeader{Schema: SchemaVersion, Manifest: mustFingerprint(t, s)}, s.store.header)
+	assert.Len(t, logLines(t, logPath), 2)
+}
+
+func mustFingerprint(t *testing.T, s *Session) string {
+	t.Helper()
+	fingerprint, err := manifestFingerprint(s.sources)
+	require.NoError(t, err)
+	return fingerprint
+}
+
+func TestUpgradeLog_RejectsNewerSchemas(t *testing.T) {
+	_, dir := reloadTestSession(t)
+	logPath := filepath.Join(dir, "newer.jsonl")
+	writeFile(t, logPath, `{"type":"LogHeader","data":{"schema":99}}`+"\n")
+
+	_, err := NewSession([]string{dir}, logPath)
+	assert.ErrorContains(t, err, "event log schema 99 is newer than this engine supports (1)")
+}
+
+func TestMigrateEntries_RunsTheChainInOrder(t *testing.T) {
+	saved := migrations
+	t.Cleanup(func() { migrations = saved })
+	migrations = nil
+	RegisterMigration(2, func(e EventWrapper) ([]EventWrapper, error) {
+		return nil, errors.New("replaced")
+	})
+	RegisterMigration(1, func(e EventWrapper) ([]EventWrapper, error) {
+		// Split an event type in two
+		if e.Type == "StatusEvent" {
+			return []EventWrapper{
+				{Type: "ConditionEvent", Data: e.Data},
+				{Type: "HintEvent", Data: json.RawMessage(`{"message":"status"}`)},
+			}, nil
+		}
+		return []EventWrapper{e}, nil
+	})
+	RegisterMigration(2, func(e EventWrapper) ([]EventWrapper, error) {
+		// Rename a field
+		if e.Type == "ConditionEvent" {
+			e.Data = json.RawMessage(strings.Replace(string(e.Data), `"who"`, `"actor_id"`, 1))
+		}
+		return []EventWrapper{e}, nil
+	})
+
+	entries := []EventWrapper{
+		{Type: "StatusEvent", Data: json.RawMessage(`{"who":"elara"}`)},
+		{Type: "SecretEvent", Data: json.RawMessage(`{"id":1,"events":[{"type":"StatusEvent","data":{"who":"goblin"}}]}`)},
+	}
+	migrated, err := migrateEntries(entries, 1, 3)
+	require.NoError(t, err)
+	require.Len(t, migrated, 3)
+	assert.Equal(t, "ConditionEvent", migrated[0].Type)
+	assert.JSONEq(t, `{"actor_id":"elara"}`, string(migrated[0].Data))
+	assert.Equal(t, "HintEvent", migrated[1].Type)
+	assert.JSONEq(t, `{"id":1,"events":[
+		{"type":"ConditionEvent","data":{"actor_id":"goblin"}},
+		{"type":"HintEvent","data":{"message":"status"}}]}`, string(migrated[2].Data))
+
+	_, err = migrateEntries(entries, 1, 4)
+	assert.ErrorContains(t, err, "no migration from event schema 3 to 4")
+}
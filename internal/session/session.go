@@ -1,12 +1,16 @@
 package session
 
 import (
+	"errors"
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
 
+	"github.com/suderio/ancient-draconic/internal/data"
 	"github.com/suderio/ancient-draconic/internal/engine"
 )
 
@@ -19,21 +23,33 @@ type Session struct {
 	store    *Store
 	eval     *engine.LuaEvaluator
 	dataDirs []string
+
+	// rollFunc is the dice roller of the evaluators (nil for the default one).
+	rollFunc engine.RollFunc
+	// sources are the manifest and module files of the loaded manifest; see Watch.
+	sources []string
+	// noEntityFiles makes the event log the only source of entities (see RunScenario).
+	noEntityFiles bool
+	// dryRun keeps the events of the command being explained out of the log; see Explain.
+	dryRun bool
 }
 
 // NewSession bootstraps a manifest-driven game session.
 func NewSession(dataDirs []string, storePath string) (*Session, error) {
 	// 1. Create Lua evaluator
-	eval, err := engine.NewLuaEvaluator(nil) // Use default dice roller
+	eval, err := newEvaluator(dataDirs, nil) // Use default dice roller
 	if err != nil {
 		return nil, fmt.Errorf("failed to create evaluator: %w", err)
 	}
 
 	// 2. Load manifest from the first available location
-	m, err := findAndLoadManifest(dataDirs, eval)
+	m, sources, err := findAndLoadManifest(dataDirs, eval)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load manifest: %w", err)
 	}
+	if err := localizeManifest(dataDirs, m, eval); err != nil {
+		return nil, err
+	}
 
 	// 3. Open event store
 	store, err := NewStore(storePath)
@@ -47,36 +63,92 @@ func NewSession(dataDirs []string, storePath string) (*Session, error) {
 		store:    store,
 		eval:     eval,
 		dataDirs: dataDirs,
+		sources:  sources,
 	}
+	store.declares = s.declares
 
-	// 4. Rebuild state from event log
-	if err := s.rebuildState(); err != nil {
+	// 4. Migrate the event log to the current schema and check its manifest
+	warnings, err := s.upgradeLog()
+	if err != nil {
 		store.Close()
 		return nil, err
 	}
+	for _, w := range warnings {
+		fmt.Printf("Warning: %s\n", w)
+	}
 
-	// 5. Load entity data files (characters/monsters) into state
-	if err := s.loadEntities(); err != nil {
-		// Non-fatal: entities can be added via commands too
-		fmt.Printf("Warning: %v\n", err)
+	// 5. Load entity data files and rebuild state from the event log
+	if err := s.rebuildState(); err != nil {
+		store.Close()
+		return nil, err
 	}
 
 	return s, nil
 }
 
+// Frontends a command can be issued from, recorded in the envelopes of its events.
+const (
+	FrontendCLI      = "cli"
+	FrontendTUI      = "tui"
+	FrontendTelegram = "telegram"
+)
+
 // Execute takes a raw command string, parses it, executes the command,
 // applies and persists the resulting events, and returns them.
 // It is thread-safe for concurrent calls (e.g., from TUI and Telegram).
+// The events are recorded as issued from the CLI; frontends use ExecuteFrom.
 func (s *Session) Execute(input string) ([]engine.Event, error) {
+	return s.ExecuteFrom(FrontendCLI, input)
+}
+
+// ExecuteFrom executes a command issued from the given frontend. The logged events are
+// returned as engine.RecordedEvent, with the envelopes of their log entries, and all of them
+// render their messages in the session's locale.
+func (s *Session) ExecuteFrom(frontend, input string) ([]engine.Event, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	events, err := s.execute(frontend, input)
+	return s.localize(events), err
+}
+
+// Explain executes a command like ExecuteFrom and also returns a trace of its evaluation: the
+// prereqs, phases and steps with their inputs and results, the dice rolled, the hooks the
+// command triggered and the events emitted. It is a dry run: the command runs against a copy of
+// the state and nothing is logged, so the game is unchanged. Undo and reload cannot be explained.
+func (s *Session) Explain(frontend, input string) ([]engine.Event, *engine.Trace, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	trace := engine.NewTrace(input)
+	s.eval.SetTrace(trace)
+	defer s.eval.SetTrace(nil)
 
+	state := s.state
+	s.state, s.dryRun = state.Clone(), true
+	defer func() { s.state, s.dryRun = state, false }()
+
+	events, err := s.execute(frontend, input)
+	trace.Fail(err)
+	return s.localize(events), trace, err
+}
+
+// localize makes events render their messages with the manifest's catalog.
+func (s *Session) localize(events []engine.Event) []engine.Event {
+	for i, evt := range events {
+		events[i] = &engine.LocalizedEvent{Event: evt, Catalog: s.manifest.Catalog}
+	}
+	return events
+}
+
+func (s *Session) execute(frontend, input string) ([]engine.Event, error) {
 	parsed := ParseInput(input)
+	env := engine.Envelope{Actor: parsed.ActorID, Frontend: frontend, Command: input}
 
 	if parsed.Command == "" {
-		return nil, fmt.Errorf("empty command")
+		return nil, s.manifest.Catalog.Error("command.empty")
 	}
 
+	names := engine.DisplayNames(s.state)
 	events, err := engine.ExecuteCommand(
 		parsed.Command,
 		parsed.ActorID,
@@ -92,17 +164,49 @@ func (s *Session) Execute(input string) ([]engine.Event, error) {
 
 	var finalEvents []engine.Event
 	queue := events
+	envelopes := make(map[engine.Event]engine.Envelope)
+	own := make(map[engine.Event]bool, len(events))
+	for _, evt := range events {
+		own[evt] = true
+	}
 
 	for len(queue) > 0 {
 		evt := queue[0]
 		queue = queue[1:]
 
+		if s.dryRun {
+			switch evt.(type) {
+			case *engine.UndoRequestEvent, *engine.ReloadRequestEvent:
+				return nil, s.manifest.Catalog.Error("session.explain.unsupported", parsed.Command)
+			}
+		}
 		if req, ok := evt.(*engine.UndoRequestEvent); ok {
 			return s.handleUndoRequest(req)
 		}
-		if err := s.applyAndPersist(evt); err != nil {
+		if _, ok := evt.(*engine.ReloadRequestEvent); ok {
+			if err := s.reload(); err != nil {
+				return nil, err
+			}
+			return []engine.Event{&engine.HintEvent{MessageStr: s.manifest.Catalog.Text("session.reloaded")}}, nil
+		}
+		if req, ok := evt.(*engine.SpawnRequestEvent); ok {
+			created, err := s.handleSpawnRequest(req)
+			if err != nil {
+				return nil, err
+			}
+			for _, evt := range created {
+				own[evt] = true
+			}
+			queue = append(created, queue...)
+			continue
+		}
+		logged, err := s.applyAndPersist(evt, env)
+		if err != nil {
 			return nil, err
 		}
+		if logged.Seq > 0 {
+			envelopes[evt] = logged
+		}
 		finalEvents = append(finalEvents, evt)
 
 		// Check for triggered hooks
@@ -116,9 +220,38 @@ func (s *Session) Execute(input string) ([]engine.Event, error) {
 		}
 	}
 
+	finalEvents = s.narrate(parsed.Command, finalEvents, own, names)
+	for i, evt := range finalEvents {
+		if logged, ok := envelopes[engine.Unwrap(evt)]; ok {
+			finalEvents[i] = &engine.RecordedEvent{Event: evt, Envelope: logged}
+		}
+	}
 	return finalEvents, nil
 }
 
+// narrate gives the events the messages of the manifest's formatters. The command's own
+// formatters only apply to the events it emitted, not to those of the hooks it triggered.
+// Formatters see the entities' names from before and after the command, so that entities it
+// removed are still named.
+func (s *Session) narrate(cmdName string, events []engine.Event, own map[engine.Event]bool, names map[string]string) []engine.Event {
+	if len(s.manifest.Formatters) == 0 && len(s.manifest.Commands[cmdName].Format) == 0 {
+		return events
+	}
+	maps.Copy(names, engine.DisplayNames(s.state))
+	narrated := make([]engine.Event, len(events))
+	for i, evt := range events {
+		source := ""
+		if own[evt] {
+			source = cmdName
+		}
+		if formatter, ok := s.manifest.Formatter(source, evt.Type()); ok {
+			evt = engine.NarrateEvent(evt, formatter, names, s.eval)
+		}
+		narrated[i] = evt
+	}
+	return narrated
+}
+
 // handleUndoRequest delegates the engine's undo request to session log rewinding logic.
 func (s *Session) handleUndoRequest(req *engine.UndoRequestEvent) ([]engine.Event, error) {
 	if req.Turn > 0 {
@@ -130,7 +263,7 @@ func (s *Session) handleUndoRequest(req *engine.UndoRequestEvent) ([]engine.Even
 
 	steps := req.Steps
 	if steps < 1 {
-		return nil, fmt.Errorf("steps must be at least 1")
+		return nil, s.manifest.Catalog.Error("session.undo.steps")
 	}
 
 	undone, err := s.Undo(steps)
@@ -138,10 +271,88 @@ func (s *Session) handleUndoRequest(req *engine.UndoRequestEvent) ([]engine.Even
 		return nil, err
 	}
 
-	msg := fmt.Sprintf("Undid %d event(s). State rewound.", undone)
+	msg := s.manifest.Catalog.Text("session.undo.done", undone)
 	return []engine.Event{&engine.HintEvent{MessageStr: msg}}, nil
 }
 
+// handleSpawnRequest resolves the requested template and instantiates the new entities.
+func (s *Session) handleSpawnRequest(req *engine.SpawnRequestEvent) ([]engine.Event, error) {
+	template, err := s.findTemplate(req.Template)
+	if err != nil {
+		return nil, err
+	}
+	return engine.SpawnEntities(s.state, template, req.Name, req.Count, s.eval)
+}
+
+// findTemplate looks up an entity template and merges in the templates it extends.
+func (s *Session) findTemplate(name string) (*engine.Entity, error) {
+	e, err := s.lookupTemplate(name, 0)
+	if err != nil {
+		return nil, err
+	}
+	return engine.ResolveExtends(e, s.lookupTemplate)
+}
+
+// lookupTemplate finds an entity template by file name in the entity directories of
+// every data directory, accepting "giant_rat" and "giant-rat" spellings. Files that are
+// not in the engine entity schema are skipped. After the entity files, the template is taken
+// from the SRD monsters or character sheets (campaign and world overrides first, then the
+// embedded SRD). The first shadowed definitions found are skipped; see engine.TemplateLookup.
+func (s *Session) lookupTemplate(name string, shadowed int) (*engine.Entity, error) {
+	name = strings.ToLower(name)
+	dashed := strings.ReplaceAll(name, "_", "-")
+	candidates := []string{name}
+	for _, c := range []string{dashed, strings.ReplaceAll(name, "-", "_")} {
+		if !slices.Contains(candidates, c) {
+			candidates = append(candidates, c)
+		}
+	}
+	var sheetDirs []string
+	for _, dir := range s.dataDirs {
+		found := false
+		for _, sub := range entitySubdirs {
+			for _, c := range candidates {
+				path := filepath.Join(dir, sub, c+".yaml")
+				if _, err := os.Stat(path); err != nil {
+					continue
+				}
+				e, err := engine.LoadEntity(path)
+				if err != nil {
+					return nil, err
+				}
+				if e.ID == "" && e.Extends == "" {
+					continue
+				}
+				// An entity file is not an SRD sheet, so this directory has none of that name
+				found = true
+				if shadowed > 0 {
+					shadowed--
+					continue
+				}
+				if e.ID == "" {
+					e.ID = c
+				}
+				return e, nil
+			}
+		}
+		if !found {
+			sheetDirs = append(sheetDirs, dir)
+		}
+	}
+
+	loader := data.NewLoader(sheetDirs)
+	if m, err := loader.LoadMonster(dashed); err == nil {
+		if shadowed == 0 {
+			return m.ToEntity(), nil
+		}
+		shadowed--
+	}
+	if c, err := loader.LoadCharacter(name); err == nil && shadowed == 0 {
+		return c.ToEntity(loader)
+	}
+	return nil, fmt.Errorf("unknown template: %s", name)
+}
+
 // undoToBoundary walks the event log backwards to find the Nth occurrence of the
 // given boundary event type and truncates the log to that point.
 func (s *Session) undoToBoundary(eventType string, count int) ([]engine.Event, error) {
@@ -167,19 +378,19 @@ func (s *Session) undoToBoundary(eventType string, count int) ([]engine.Event, e
 	}
 
 	if found < count {
-		return nil, fmt.Errorf("cannot undo %d %s(s): only %d found in the log", count, eventType, found)
+		return nil, s.manifest.Catalog.Error("session.undo.boundary_count", count, eventType, found)
 	}
 
 	undone := len(events) - keepN
 	if undone == 0 {
-		return []engine.Event{&engine.HintEvent{MessageStr: "Nothing to undo."}}, nil
+		return []engine.Event{&engine.HintEvent{MessageStr: s.manifest.Catalog.Text("session.undo.nothing")}}, nil
 	}
 
 	if _, err := s.Undo(undone); err != nil {
 		return nil, err
 	}
 
-	msg := fmt.Sprintf("Undid %d event(s) to %s boundary. State rewound.", undone, eventType)
+	msg := s.manifest.Catalog.Text("session.undo.boundary", undone, eventType)
 	return []engine.Event{&engine.HintEvent{MessageStr: msg}}, nil
 }
 
@@ -204,13 +415,47 @@ func isGM(actorID string) bool {
 	return strings.ToUpper(actorID) == "GM"
 }
 
-// State returns the current game state.
+// State returns a copy of the current, unredacted game state. It is meant for the GM's own
+// frontends.
 func (s *Session) State() *engine.GameState {
-	return s.state
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.state.Clone()
+}
+
+// View returns the game state as seen by the viewer, redacted per the manifest's visibility rules.
+// Player-facing frontends must use View instead of State.
+func (s *Session) View(viewer engine.Viewer) *engine.GameState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return engine.ProjectState(s.state, s.manifest.Visibility, viewer)
+}
+
+// ViewEvents returns the events as the viewer may see them, dropping those it must not see
+// (see engine.ProjectEvent). Player-facing frontends must show events through ViewEvents.
+func (s *Session) ViewEvents(events []engine.Event, viewer engine.Viewer) []engine.Event {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var visible []engine.Event
+	for _, evt := range events {
+		if evt = engine.ProjectEvent(evt, s.manifest.Visibility, viewer); evt != nil {
+			visible = append(visible, evt)
+		}
+	}
+	return s.localize(visible)
+}
+
+// Catalog returns the catalog of the session's messages, for frontends to render their own.
+func (s *Session) Catalog() *engine.Catalog {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.manifest.Catalog
 }
 
 // Manifest returns the loaded manifest for autocomplete and help.
 func (s *Session) Manifest() *engine.Manifest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	return s.manifest
 }
 
@@ -231,7 +476,7 @@ func (s *Session) Undo(steps int) (int, error) {
 	}
 
 	if steps > total {
-		return 0, fmt.Errorf("cannot undo %d events: only %d events in the log", steps, total)
+		return 0, s.manifest.Catalog.Error("session.undo.too_many", steps, total)
 	}
 
 	keepN := total - steps
@@ -244,19 +489,37 @@ func (s *Session) Undo(steps int) (int, error) {
 	if err := s.rebuildState(); err != nil {
 		return 0, fmt.Errorf("failed to rebuild state after undo: %w", err)
 	}
-	if err := s.loadEntities(); err != nil {
-		fmt.Printf("Warning: %v\n", err)
-	}
 
 	return steps, nil
 }
 
-// rebuildState replays all persisted events to reconstruct the in-memory state.
+// rebuildState loads the entity data files and replays all persisted events on top of them
+// to reconstruct the in-memory state, then re-binds persisted hooks to the manifest. When a
+// snapshot matches the log, the state starts from it and only the events after it are replayed.
 func (s *Session) rebuildState() error {
-	events, err := s.store.Load()
+	_, lines, err := s.store.readLog()
 	if err != nil {
 		return fmt.Errorf("failed to load event log: %w", err)
 	}
+	snap := s.restoreSnapshot(lines)
+	if snap != nil {
+		s.state, lines = snap.State, lines[snap.Offset:]
+	}
+	events, err := decodeLines(lines)
+	if err != nil {
+		return fmt.Errorf("failed to load event log: %w", err)
+	}
+
+	// Declared events replay through the manifest's reducers
+	engine.BindEvents(s.state, s.manifest, s.eval)
+
+	// Entities must exist before replay so that events targeting them (e.g. hooks) apply.
+	if snap == nil && !s.noEntityFiles {
+		if err := s.loadEntities(); err != nil {
+			// Non-fatal: entities can be added via commands too
+			fmt.Printf("Warning: %v\n", err)
+		}
+	}
 
 	for _, evt := range events {
 		if err := evt.Apply(s.state); err != nil {
@@ -264,52 +527,144 @@ func (s *Session) rebuildState() error {
 		}
 	}
 
+	if err := engine.BindHooks(s.state, s.manifest); err != nil {
+		return fmt.Errorf("failed to restore hooks: %w", err)
+	}
+
 	return nil
 }
 
-// applyAndPersist commits an event to both the in-memory state and the persistent store.
-func (s *Session) applyAndPersist(evt engine.Event) error {
+// applyAndPersist commits an event to both the in-memory state and the persistent store, and
+// returns the envelope it was logged with. The event is applied before it is logged, so one that
+// fails to apply is not logged. Most events check what they need before changing the state;
+// those that may fail halfway through are applied to a copy of it, which replaces the state once
+// the event is logged.
+func (s *Session) applyAndPersist(evt engine.Event, env engine.Envelope) (engine.Envelope, error) {
 	// HintEvents are display-only and should not be persisted
 	if _, isHint := evt.(*engine.HintEvent); isHint {
-		return nil
+		return engine.Envelope{}, nil
+	}
+
+	next := s.state
+	if appliesInParts(evt) {
+		next = s.state.Clone()
+	}
+	if err := evt.Apply(next); err != nil {
+		return env, fmt.Errorf("failed to apply event: %w", err)
+	}
+	if s.dryRun {
+		s.state = next
+		return engine.Envelope{}, nil
 	}
 
-	if err := s.store.Append(evt); err != nil {
-		return fmt.Errorf("failed to persist event: %w", err)
+	env, err := s.store.AppendRecord(evt, env)
+	if err != nil {
+		return env, fmt.Errorf("failed to persist event: %w", err)
 	}
+	s.state = next
 
-	if err := evt.Apply(s.state); err != nil {
-		return fmt.Errorf("failed to apply event: %w", err)
+	// Snapshots only speed up loading: failing to write one is not an error
+	if count, _ := s.store.position(); count%snapshotInterval == 0 {
+		if err := s.writeSnapshot(); err != nil {
+			fmt.Printf("Warning: %v\n", err)
+		}
 	}
+	return env, nil
+}
 
-	return nil
+// declares reports whether the session's manifest declares an event type. The store logs only
+// the declared events it declares, so that a reload that drops a type stops its events too.
+func (s *Session) declares(typeName string) bool {
+	return s.state.Declares(typeName)
+}
+
+// appliesInParts reports whether an event applies several changes, any of which can fail after
+// the earlier ones are made: declared events, whose reducer results are applied one by one, and
+// secrets wrapping more than one event.
+func appliesInParts(evt engine.Event) bool {
+	switch e := engine.Unwrap(evt).(type) {
+	case *engine.DeclaredEvent:
+		return true
+	case *engine.SecretEvent:
+		return len(e.Events) > 1 || slices.ContainsFunc(e.Events, appliesInParts)
+	}
+	return false
+}
+
+// newEvaluator creates a Lua evaluator whose srd() reads records through the data directories.
+func newEvaluator(dataDirs []string, rollFunc engine.RollFunc) (*engine.LuaEvaluator, error) {
+	eval, err := engine.NewLuaEvaluator(rollFunc)
+	if err != nil {
+		return nil, err
+	}
+
+	// Expose SRD records, with campaign and world overrides, to srd() in formulas
+	loader := data.NewLoader(dataDirs)
+	eval.SetDataSource(func(category, index string) (map[string]any, error) {
+		record, err := loader.LoadRecord(category, index)
+		if errors.Is(err, data.ErrNotFound) {
+			return nil, fmt.Errorf("%w: %w", engine.ErrRecordNotFound, err)
+		}
+		return record, err
+	})
+	return eval, nil
 }
 
 // findAndLoadManifest searches data directories for a manifest.lua or manifest.yaml file.
-func findAndLoadManifest(dataDirs []string, eval *engine.LuaEvaluator) (*engine.Manifest, error) {
-	for _, dir := range dataDirs {
+// A manifest.lua is followed by the overlays of its directory and of the more specific
+// directories before it. It also returns the files the manifest was loaded from: the manifest,
+// its overlays and its required modules.
+func findAndLoadManifest(dataDirs []string, eval *engine.LuaEvaluator) (*engine.Manifest, []string, error) {
+	for i, dir := range dataDirs {
 		luaPath := filepath.Join(dir, "manifest.lua")
 		if _, err := os.Stat(luaPath); err == nil {
-			return eval.LoadManifestLua(luaPath)
+			// Modules resolve through every data directory, so campaigns can override world modules
+			eval.SetModulePaths(dataDirs...)
+			overlays := findOverlays(dataDirs[:i+1])
+			m, err := eval.LoadManifestLua(luaPath, overlays...)
+			sources := append([]string{luaPath}, overlays...)
+			return m, append(sources, eval.ModuleFiles()...), err
 		}
 
 		yamlPath := filepath.Join(dir, "manifest.yaml")
 		if _, err := os.Stat(yamlPath); err == nil {
-			return engine.LoadManifest(yamlPath) // legacy fallback
+			m, err := engine.LoadManifest(yamlPath) // legacy fallback
+			return m, []string{yamlPath}, err
 		}
 	}
-	return nil, fmt.Errorf("neither manifest.lua nor manifest.yaml found in any of: %s", strings.Join(dataDirs, ", "))
+	return nil, nil, fmt.Errorf("neither manifest.lua nor manifest.yaml found in any of: %s", strings.Join(dataDirs, ", "))
+}
+
+// findOverlays lists the overlays/*.lua files of the data directories, from the least specific
+// directory (the last one) to the most specific, each directory's files in name order.
+func findOverlays(dataDirs []string) []string {
+	var overlays []string
+	for i := len(dataDirs) - 1; i >= 0; i-- {
+		files, _ := filepath.Glob(filepath.Join(dataDirs[i], "overlays", "*.lua"))
+		overlays = append(overlays, files...)
+	}
+	return overlays
 }
 
+// entitySubdirs lists the directories, relative to each data directory, that hold entity files.
+var entitySubdirs = []string{"characters", "monsters", "data/characters", "data/monsters"}
+
 // loadEntities scans data directories for character and monster YAML files.
 func (s *Session) loadEntities() error {
+	loader := data.NewLoader(s.dataDirs)
 	for _, dir := range s.dataDirs {
-		for _, sub := range []string{"characters", "monsters", "data/characters", "data/monsters"} {
-			entities, _ := loadEntitiesFromDir(filepath.Join(dir, sub))
+		for _, sub := range entitySubdirs {
+			entities, _ := loadEntitiesFromDir(filepath.Join(dir, sub), loader)
 			for _, e := range entities {
-				if _, exists := s.state.Entities[e.ID]; !exists {
-					s.state.Entities[e.ID] = e
+				if _, exists := s.state.Entities[e.ID]; exists {
+					continue
 				}
+				resolved, err := engine.ResolveExtends(e, s.lookupTemplate)
+				if err != nil {
+					fmt.Printf("Warning: %v\n", err)
+					continue
+				}
+				s.state.Entities[e.ID] = resolved
 			}
 		}
 	}
@@ -317,7 +672,9 @@ func (s *Session) loadEntities() error {
 }
 
 // loadEntitiesFromDir reads all YAML files in a directory and loads them as entities.
-func loadEntitiesFromDir(dir string) ([]*engine.Entity, error) {
+// Files without an engine id are read as SRD character sheets or monsters, depending on the directory,
+// unless they extend a template, in which case the file name is used as the id.
+func loadEntitiesFromDir(dir string, loader *data.Loader) ([]*engine.Entity, error) {
 	entries, err := filepath.Glob(filepath.Join(dir, "*.yaml"))
 	if err != nil || len(entries) == 0 {
 		return nil, err
@@ -329,7 +686,39 @@ func loadEntitiesFromDir(dir string) ([]*engine.Entity, error) {
 		if err != nil {
 			continue
 		}
+		switch {
+		case e.ID == "" && e.Extends != "":
+			e.ID = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		case e.ID == "":
+			if e, err = loadSheet(path, filepath.Base(dir) == "monsters", loader); err != nil {
+				fmt.Printf("Warning: %v\n", err)
+				continue
+			}
+		}
 		result = append(result, e)
 	}
 	return result, nil
 }
+
+// loadSheet converts an entity file written in the SRD schema into an entity. The file name
+// becomes the entity ID, which is also how Telegram users are mapped to their characters.
+func loadSheet(path string, monster bool, loader *data.Loader) (*engine.Entity, error) {
+	var e *engine.Entity
+	if monster {
+		m, err := data.LoadMonsterFile(path)
+		if err != nil {
+			return nil, err
+		}
+		e = m.ToEntity()
+	} else {
+		c, err := data.LoadCharacterFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if e, err = c.ToEntity(loader); err != nil {
+			return nil, err
+		}
+	}
+	e.ID = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	return e, nil
+}
@@ -0,0 +1,157 @@
+package session
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/suderio/ancient-draconic/internal/engine"
+)
+
+// snapshotInterval is the number of logged events between two snapshots of the game state.
+var snapshotInterval = 500
+
+// keptSnapshots is the number of snapshots kept, the newest ones, so that undoing past the
+// latest one still finds an earlier one.
+const keptSnapshots = 3
+
+// Snapshot is the game state after the first Offset entries of the event log, saved so that
+// loading a campaign only replays the entries after it. It is only used while it matches the
+// log, the schema and the files the state was built from.
+type Snapshot struct {
+	Offset  int               `json:"offset"`  // number of log entries the state includes
+	Digest  string            `json:"digest"`  // hash of the lines of those entries
+	Schema  int               `json:"schema"`  // event schema of the log
+	Sources string            `json:"sources"` // fingerprint of the manifest and entity files
+	State   *engine.GameState `json:"state"`
+}
+
+// snapshotDir is where the snapshots of an event log are kept: log.jsonl.snapshots.
+func snapshotDir(logPath string) string {
+	return logPath + ".snapshots"
+}
+
+// writeSnapshot saves the current state as a snapshot at the current end of the log, and
+// removes all but the newest keptSnapshots.
+func (s *Session) writeSnapshot() error {
+	sources, err := s.sourcesFingerprint()
+	if err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	offset, digest := s.store.position()
+	data, err := json.Marshal(Snapshot{Offset: offset, Digest: digest, Schema: s.store.header.Schema, Sources: sources, State: s.state})
+	if err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+
+	dir := snapshotDir(s.store.file.Name())
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	tmp := filepath.Join(dir, ".snapshot.tmp")
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	if err := os.Rename(tmp, filepath.Join(dir, snapshotName(offset))); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+
+	offsets := snapshotOffsets(dir)
+	for _, old := range offsets[min(keptSnapshots, len(offsets)):] {
+		os.Remove(filepath.Join(dir, snapshotName(old)))
+	}
+	return nil
+}
+
+// restoreSnapshot finds the newest snapshot that matches the log's entry lines and the current
+// schema and sources, and returns it. Snapshots that do not match are removed; nil is returned
+// when none does, and the whole log must be replayed.
+func (s *Session) restoreSnapshot(lines [][]byte) *Snapshot {
+	dir := snapshotDir(s.store.file.Name())
+	offsets := snapshotOffsets(dir)
+	if len(offsets) == 0 {
+		return nil
+	}
+	sources, err := s.sourcesFingerprint()
+	if err != nil {
+		return nil
+	}
+
+	// Digests of the log's prefixes at the snapshots' offsets
+	digests := make(map[int]string, len(offsets))
+	h := sha256.New()
+	for i, line := range lines {
+		h.Write(append(line, '\n'))
+		if slices.Contains(offsets, i+1) {
+			digests[i+1] = hex.EncodeToString(h.Sum(nil))
+		}
+	}
+
+	for _, offset := range offsets {
+		path := filepath.Join(dir, snapshotName(offset))
+		snap, err := readSnapshot(path)
+		if err == nil && snap.Offset == offset && snap.Digest == digests[offset] &&
+			snap.Schema == s.store.header.Schema && snap.Sources == sources && snap.State != nil {
+			return snap
+		}
+		os.Remove(path)
+	}
+	return nil
+}
+
+// sourcesFingerprint identifies the files a replay builds the state from: the manifest with
+// its overlays and modules, the settings and locale files, whose messages secrets keep, and,
+// unless the log is the only source of entities, the entity files.
+func (s *Session) sourcesFingerprint() (string, error) {
+	files := slices.Clone(s.sources)
+	for _, dir := range s.dataDirs {
+		settings, _ := filepath.Glob(filepath.Join(dir, "settings.yaml"))
+		locales, _ := filepath.Glob(filepath.Join(dir, "locales", "*.yaml"))
+		files = append(append(files, settings...), locales...)
+	}
+	if !s.noEntityFiles {
+		for _, dir := range s.dataDirs {
+			for _, sub := range entitySubdirs {
+				matches, _ := filepath.Glob(filepath.Join(dir, sub, "*.yaml"))
+				files = append(files, matches...)
+			}
+		}
+	}
+	return manifestFingerprint(files)
+}
+
+func readSnapshot(path string) (*Snapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var snap Snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return nil, err
+	}
+	return &snap, nil
+}
+
+func snapshotName(offset int) string {
+	return fmt.Sprintf("%010d.json", offset)
+}
+
+// snapshotOffsets returns the offsets of the snapshots in a directory, the newest first.
+func snapshotOffsets(dir string) []int {
+	matches, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	var offsets []int
+	for _, path := range matches {
+		if offset, err := strconv.Atoi(strings.TrimSuffix(filepath.Base(path), ".json")); err == nil {
+			offsets = append(offsets, offset)
+		}
+	}
+	slices.Sort(offsets)
+	slices.Reverse(offsets)
+	return offsets
+}
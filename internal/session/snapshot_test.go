@@ -0,0 +1,122 @@
+package session
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const snapshotManifest = `
+local function mark(c) return { steps = { { name = c, value = "condition('" .. c .. "')" } } } end
+commands = {
+    bless = { name = "bless", actor = mark("blessed") },
+    curse = { name = "curse", actor = mark("cursed") },
+    hex = { name = "hex", actor = mark("hexed") },
+}
+`
+
+// snapshotCampaign creates a campaign that snapshots every two events, plays three commands in
+// it and returns its directory and log.
+func snapshotCampaign(t *testing.T) (string, string) {
+	t.Helper()
+	interval := snapshotInterval
+	snapshotInterval = 2
+	t.Cleanup(func() { snapshotInterval = interval })
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "manifest.lua"), snapshotManifest)
+	writeFile(t, filepath.Join(dir, "characters", "elara.yaml"), "id: elara\nname: Elara\n")
+	logPath := filepath.Join(dir, "log.jsonl")
+
+	s, err := NewSession([]string{dir}, logPath)
+	require.NoError(t, err)
+	defer s.Close()
+	for _, input := range []string{"bless by: elara", "curse by: elara", "hex by: elara"} {
+		_, err := s.Execute(input)
+		require.NoError(t, err)
+	}
+	return dir, logPath
+}
+
+// markSnapshot renames elara in a snapshot, so that a state built from it is recognizable.
+func markSnapshot(t *testing.T, path string) {
+	t.Helper()
+	snap, err := readSnapshot(path)
+	require.NoError(t, err)
+	snap.State.Entities["elara"].Name = "Snapshot Elara"
+	data, err := json.Marshal(snap)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, data, 0644))
+}
+
+func TestSnapshots_LoadReplaysOnlyTheTail(t *testing.T) {
+	dir, logPath := snapshotCampaign(t)
+	path := filepath.Join(snapshotDir(logPath), "0000000002.json")
+	require.FileExists(t, path)
+	markSnapshot(t, path)
+
+	s, err := NewSession([]string{dir}, logPath)
+	require.NoError(t, err)
+	defer s.Close()
+	elara := s.State().Entities["elara"]
+	assert.Equal(t, "Snapshot Elara", elara.Name)
+	assert.Equal(t, []string{"blessed", "cursed", "hexed"}, elara.Conditions)
+
+	// Undoing past the snapshot replays the whole log and drops the snapshot
+	_, err = s.Undo(2)
+	require.NoError(t, err)
+	elara = s.State().Entities["elara"]
+	assert.Equal(t, "Elara", elara.Name)
+	assert.Equal(t, []string{"blessed"}, elara.Conditions)
+	assert.NoFileExists(t, path)
+}
+
+func TestSnapshots_AreDroppedWhenTheirSourcesChange(t *testing.T) {
+	dir, logPath := snapshotCampaign(t)
+	path := filepath.Join(snapshotDir(logPath), "0000000002.json")
+	markSnapshot(t, path)
+	writeFile(t, filepath.Join(dir, "characters", "elara.yaml"), "id: elara\nname: Elara the Bold\n")
+
+	s, err := NewSession([]string{dir}, logPath)
+	require.NoError(t, err)
+	defer s.Close()
+	elara := s.State().Entities["elara"]
+	assert.Equal(t, "Elara the Bold", elara.Name)
+	assert.Equal(t, []string{"blessed", "cursed", "hexed"}, elara.Conditions)
+	assert.NoFileExists(t, path)
+}
+
+func TestSnapshots_AreDroppedWhenTheirMessagesChange(t *testing.T) {
+	// Secrets keep messages rendered in the campaign's locale
+	for file, content := range map[string]string{
+		"settings.yaml":   "locale: pt\n",
+		"locales/en.yaml": `event.secret: "[hidden #%d] %s"`,
+	} {
+		dir, logPath := snapshotCampaign(t)
+		path := filepath.Join(snapshotDir(logPath), "0000000002.json")
+		markSnapshot(t, path)
+		writeFile(t, filepath.Join(dir, file), content)
+
+		s, err := NewSession([]string{dir}, logPath)
+		require.NoError(t, err, file)
+		assert.Equal(t, "Elara", s.State().Entities["elara"].Name, file)
+		assert.NoFileExists(t, path, file)
+		require.NoError(t, s.Close())
+	}
+}
+
+func TestSnapshots_KeepTheNewest(t *testing.T) {
+	_, logPath := snapshotCampaign(t)
+	s, err := NewSession([]string{filepath.Dir(logPath)}, logPath)
+	require.NoError(t, err)
+	defer s.Close()
+	for range 6 {
+		_, err := s.Execute("bless by: elara")
+		require.NoError(t, err)
+	}
+	assert.Equal(t, []int{8, 6, 4}, snapshotOffsets(snapshotDir(logPath)))
+}
@@ -0,0 +1,188 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/suderio/ancient-draconic/internal/engine"
+)
+
+const goblinTemplate = `id: goblin
+name: Goblin
+classes:
+  hit_dice: 2d6
+stats:
+  dex: 14
+resources:
+  hp: 7
+`
+
+func TestSpawnFromTemplate(t *testing.T) {
+	s, storePath := testSession(t)
+	dataDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dataDir, "monsters"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dataDir, "monsters", "goblin.yaml"), []byte(goblinTemplate), 0644))
+	s.dataDirs = []string{dataDir}
+
+	events, err := s.Execute("spawn goblin count: 4")
+	require.NoError(t, err)
+	require.Len(t, events, 4)
+
+	for _, id := range []string{"Goblin_A", "Goblin_B", "Goblin_C", "Goblin_D"} {
+		require.Contains(t, s.State().Entities, id)
+		assert.Equal(t, 10, s.State().Entities[id].Resources["hp"])
+		assert.Equal(t, 14, s.State().Entities[id].Stats["dex"])
+	}
+
+	_, err = s.Execute("despawn of: Goblin_B")
+	require.NoError(t, err)
+	assert.NotContains(t, s.State().Entities, "Goblin_B")
+
+	_, err = s.Execute("spawn dragon")
+	assert.ErrorContains(t, err, "unknown template")
+	s.Close()
+
+	// Spawned and removed entities are rebuilt from the log alone
+	store, err := NewStore(storePath)
+	require.NoError(t, err)
+	defer store.Close()
+	events, err = store.Load()
+	require.NoError(t, err)
+
+	state := engine.NewGameState()
+	for _, evt := range events {
+		require.NoError(t, evt.Apply(state))
+	}
+	assert.Contains(t, state.Entities, "Goblin_A")
+	assert.NotContains(t, state.Entities, "Goblin_B")
+	assert.Len(t, state.Entities, 3)
+}
+
+func TestSpawnFromSRD(t *testing.T) {
+	s, _ := testSession(t)
+	defer s.Close()
+
+	_, err := s.Execute("spawn giant rat count: 2")
+	require.NoError(t, err)
+
+	rat := s.State().Entities["Giant_Rat_A"]
+	require.NotNil(t, rat)
+	assert.Contains(t, s.State().Entities, "Giant_Rat_B")
+	assert.Equal(t, "small", rat.Classes["size"])
+	assert.Equal(t, 10, rat.Resources["hp"])
+	assert.Contains(t, rat.Actions, "bite")
+}
+
+func TestLoadEntities_SRDCharacterSheet(t *testing.T) {
+	s, _ := testSession(t)
+	defer s.Close()
+	dataDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dataDir, "characters"), 0755))
+	sheet := `name: Brom
+race: dwarf
+proficiency_bonus: 2
+hit_dice: 1d10
+strength: 16
+dexterity: 10
+constitution: 14
+intelligence: 10
+wisdom: 12
+charisma: 8
+`
+	require.NoError(t, os.WriteFile(filepath.Join(dataDir, "characters", "brom.yaml"), []byte(sheet), 0644))
+	s.dataDirs = []string{dataDir}
+
+	require.NoError(t, s.loadEntities())
+	brom := s.State().Entities["brom"]
+	require.NotNil(t, brom)
+	assert.Equal(t, "Brom", brom.Name)
+	// The sheet's scores are final; the race gives the speed
+	assert.Equal(t, 14, brom.Stats["con"])
+	assert.Equal(t, 25, brom.Resources["speed"])
+	assert.Equal(t, 12, brom.Resources["hp"])
+}
+
+func TestLoadEntities_Extends(t *testing.T) {
+	s, _ := testSession(t)
+	defer s.Close()
+	dataDir := t.TempDir()
+	monsters := filepath.Join(dataDir, "monsters")
+	require.NoError(t, os.MkdirAll(monsters, 0755))
+	boss := `name: Goblin Boss
+extends: goblin
+stats:
+  str: 10
+resources:
+  hp: 21
+`
+	loopA := "id: loop_a\nextends: loop_b\n"
+	loopB := "id: loop_b\nextends: loop_a\n"
+	require.NoError(t, os.WriteFile(filepath.Join(monsters, "goblin_boss.yaml"), []byte(boss), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(monsters, "loop_a.yaml"), []byte(loopA), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(monsters, "loop_b.yaml"), []byte(loopB), 0644))
+	s.dataDirs = []string{dataDir}
+
+	require.NoError(t, s.loadEntities())
+	e := s.State().Entities["goblin_boss"]
+	require.NotNil(t, e)
+	assert.Equal(t, "Goblin Boss", e.Name)
+	// Inherited from the SRD goblin, with overrides applied
+	assert.Equal(t, 14, e.Stats["dex"])
+	assert.Equal(t, 10, e.Stats["str"])
+	assert.Equal(t, 21, e.Resources["hp"])
+	assert.Contains(t, e.Actions, "scimitar")
+
+	// Entities in a template cycle are skipped
+	assert.NotContains(t, s.State().Entities, "loop_a")
+	assert.NotContains(t, s.State().Entities, "loop_b")
+
+	// Spawned copies keep the hp the template declares over the inherited hit dice
+	_, err := s.Execute("spawn goblin_boss")
+	require.NoError(t, err)
+	assert.Equal(t, 21, s.State().Entities["Goblin_Boss"].Resources["hp"])
+}
+
+func TestLoadEntities_ExtendsTheSRDTemplateItShadows(t *testing.T) {
+	s, _ := testSession(t)
+	defer s.Close()
+	dataDir := t.TempDir()
+	monsters := filepath.Join(dataDir, "monsters")
+	require.NoError(t, os.MkdirAll(monsters, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(monsters, "goblin.yaml"), []byte("extends: goblin\nresources:\n  hp: 12\n"), 0644))
+	s.dataDirs = []string{dataDir}
+
+	require.NoError(t, s.loadEntities())
+	e := s.State().Entities["goblin"]
+	require.NotNil(t, e)
+	assert.Equal(t, 14, e.Stats["dex"])
+	assert.Equal(t, 12, e.Resources["hp"])
+	assert.Contains(t, e.Actions, "scimitar")
+
+	_, err := s.Execute("spawn goblin")
+	require.NoError(t, err)
+	assert.Equal(t, 12, s.State().Entities["Goblin"].Resources["hp"])
+}
+
+func TestView_RedactsSpawnedMonsters(t *testing.T) {
+	s, _ := testSession(t)
+	defer s.Close()
+	dataDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dataDir, "monsters"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dataDir, "monsters", "goblin.yaml"), []byte(goblinTemplate), 0644))
+	s.dataDirs = []string{dataDir}
+	s.manifest.Visibility.Resources = map[string][]engine.ResourceBand{"hp": {{Label: "healthy"}}}
+
+	_, err := s.Execute("spawn goblin")
+	require.NoError(t, err)
+
+	player := s.View(engine.ViewerFor("elara"))
+	assert.Equal(t, "healthy", player.Entities["Goblin"].Statuses["hp"])
+	assert.Empty(t, player.Entities["Goblin"].Resources)
+
+	gm := s.View(engine.ViewerFor("GM"))
+	assert.Equal(t, 10, gm.Entities["Goblin"].Resources["hp"])
+}
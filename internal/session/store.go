@@ -2,45 +2,102 @@ package session
 
 import (
 	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"hash"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/suderio/ancient-draconic/internal/engine"
 )
 
-// EventWrapper serializes polymorphic engine events to JSONL.
+// EventWrapper serializes polymorphic engine events to JSONL, along with the envelope of the
+// entry. The log header has no envelope.
 type EventWrapper struct {
-	Type string          `json:"type"`
+	Type string `json:"type"`
+	engine.Envelope
 	Data json.RawMessage `json:"data"`
 }
 
-// Store handles append-only storage of engine events as JSONL.
+// Store handles append-only storage of engine events as JSONL. The first line of the log is
+// its header; see LogHeader.
 type Store struct {
-	file *os.File
+	file   *os.File
+	header LogHeader
+
+	// count and digest track the entries of the log: their number and a running hash of their
+	// lines, which snapshots are checked against. seq is the highest sequence number issued,
+	// which the next entry follows also after a rewrite dropped the last ones.
+	count  int
+	digest hash.Hash
+	seq    int64
+
+	// declares reports whether the session's manifest declares an event type, whose events can
+	// then be logged; nil when the store logs no declared events.
+	declares func(typeName string) bool
 }
 
-// NewStore opens or creates a JSONL event log at the given path.
+// NewStore opens or creates a JSONL event log at the given path. A new log starts with a header
+// for the current schema; a log without one predates headers and is read as legacySchema.
 func NewStore(path string) (*Store, error) {
 	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_RDWR, 0644)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open event store: %w", err)
 	}
-	return &Store{file: file}, nil
+	s := &Store{file: file}
+
+	header, lines, err := s.readLog()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	switch {
+	case header != nil:
+		s.header = *header
+	case len(lines) == 0:
+		s.header = LogHeader{Schema: SchemaVersion}
+		if err := s.writeLine(EventWrapper{Type: logHeaderType}, s.header); err != nil {
+			file.Close()
+			return nil, fmt.Errorf("failed to write log header: %w", err)
+		}
+	default:
+		s.header = LogHeader{Schema: legacySchema}
+	}
+	s.track(lines)
+	return s, nil
 }
 
-// Append marshals an engine Event and appends it as a JSONL line.
+// Append marshals an engine Event and appends it as a JSONL line. Events of types the log
+// could not be decoded with are refused.
 func (s *Store) Append(evt engine.Event) error {
-	data, err := json.Marshal(evt)
-	if err != nil {
-		return fmt.Errorf("failed to marshal event: %w", err)
+	_, err := s.AppendRecord(evt, engine.Envelope{})
+	return err
+}
+
+// AppendRecord appends an event like Append, with the actor, frontend and command of the
+// envelope. The entry gets the next sequence number and the current time, and its envelope is
+// returned.
+func (s *Store) AppendRecord(evt engine.Event, env engine.Envelope) (engine.Envelope, error) {
+	if err := engine.CheckDecodable(evt, s.declares); err != nil {
+		return env, fmt.Errorf("refusing to log event: %w", err)
+	}
+	env.Seq, env.Time = s.seq+1, time.Now().UTC()
+	if err := s.writeLine(EventWrapper{Type: evt.Type(), Envelope: env}, evt); err != nil {
+		return env, err
 	}
+	return env, nil
+}
 
-	wrapper := EventWrapper{
-		Type: evt.Type(),
-		Data: data,
+// writeLine marshals v as the data of a wrapped entry, appends it and syncs the file.
+func (s *Store) writeLine(wrapper EventWrapper, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
 	}
+	wrapper.Data = data
 
 	line, err := json.Marshal(wrapper)
 	if err != nil {
@@ -50,97 +107,119 @@ func (s *Store) Append(evt engine.Event) error {
 	if _, err := s.file.Write(append(line, '\n')); err != nil {
 		return err
 	}
+	if wrapper.Type != logHeaderType {
+		s.count++
+		s.digest.Write(append(line, '\n'))
+		s.seq = wrapper.Seq
+	}
 	return s.file.Sync()
 }
 
+// track starts tracking the entries of the log from its current lines and header. Entries
+// logged before envelopes have no sequence number; theirs is their position.
+func (s *Store) track(lines [][]byte) {
+	s.count, s.digest, s.seq = len(lines), sha256.New(), max(lastSeq(lines), s.header.Seq)
+	for _, line := range lines {
+		s.digest.Write(append(line, '\n'))
+	}
+}
+
+// lastSeq returns the sequence number of the last of the lines: the one it was logged with, or
+// its position for entries logged before envelopes.
+func lastSeq(lines [][]byte) int64 {
+	if len(lines) == 0 {
+		return 0
+	}
+	var last EventWrapper
+	if err := json.Unmarshal(lines[len(lines)-1], &last); err == nil && last.Seq > 0 {
+		return last.Seq
+	}
+	return int64(len(lines))
+}
+
+// position returns the number of entries in the log and the digest of their lines.
+func (s *Store) position() (int, string) {
+	return s.count, hex.EncodeToString(s.digest.Sum(nil))
+}
+
 // Load replays all events from the JSONL log and returns them.
 func (s *Store) Load() ([]engine.Event, error) {
-	if _, err := s.file.Seek(0, 0); err != nil {
+	_, lines, err := s.readLog()
+	if err != nil {
 		return nil, err
 	}
+	return decodeLines(lines)
+}
 
+// decodeLines decodes the events of log entry lines.
+func decodeLines(lines [][]byte) ([]engine.Event, error) {
 	var events []engine.Event
-	scanner := bufio.NewScanner(s.file)
-	for scanner.Scan() {
+	for _, line := range lines {
 		var wrapper EventWrapper
-		if err := json.Unmarshal(scanner.Bytes(), &wrapper); err != nil {
+		if err := json.Unmarshal(line, &wrapper); err != nil {
 			return nil, fmt.Errorf("failed to decode event wrapper: %w", err)
 		}
-
-		evt, err := unmarshalEvent(wrapper.Type, wrapper.Data)
+		evt, err := engine.DecodeEvent(wrapper.Type, wrapper.Data)
 		if err != nil {
 			return nil, err
 		}
 		events = append(events, evt)
 	}
-
-	return events, scanner.Err()
-}
-
-// Close flushes and closes the underlying file.
-func (s *Store) Close() error {
-	return s.file.Close()
+	return events, nil
 }
 
-// unmarshalEvent reconstructs a concrete Event from its type discriminator and JSON data.
-func unmarshalEvent(typeName string, data json.RawMessage) (engine.Event, error) {
-	var evt engine.Event
-
-	switch typeName {
-	case "LoopEvent":
-		evt = &engine.LoopEvent{}
-	case "LoopOrderAscendingEvent":
-		evt = &engine.LoopOrderAscendingEvent{}
-	case "LoopOrderEvent":
-		evt = &engine.LoopOrderEvent{}
-	case "ActorAddedEvent":
-		evt = &engine.ActorAddedEvent{}
-	case "AttributeChangedEvent":
-		evt = &engine.AttributeChangedEvent{}
-	case "AddSpentEvent":
-		evt = &engine.AddSpentEvent{}
-	case "ConditionEvent":
-		evt = &engine.ConditionEvent{}
-	case "AskIssuedEvent":
-		evt = &engine.AskIssuedEvent{}
-	case "HintEvent":
-		evt = &engine.HintEvent{}
-	case "DiceRolledEvent":
-		evt = &engine.DiceRolledEvent{}
-	case "MetadataChangedEvent":
-		evt = &engine.MetadataChangedEvent{}
-	case "CheckEvent":
-		evt = &engine.CheckEvent{}
-	case "CustomEvent":
-		evt = &engine.CustomEvent{}
-	case "TurnEndedEvent":
-		evt = &engine.TurnEndedEvent{}
-	case "TurnStartedEvent":
-		evt = &engine.TurnStartedEvent{}
-	case "RoundStartedEvent":
-		evt = &engine.RoundStartedEvent{}
-	default:
-		return nil, fmt.Errorf("unknown event type: %s", typeName)
+// entries returns the wrapped entries of the log, without decoding the events.
+func (s *Store) entries() ([]EventWrapper, error) {
+	_, lines, err := s.readLog()
+	if err != nil {
+		return nil, err
 	}
-
-	if err := json.Unmarshal(data, evt); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal %s: %w", typeName, err)
+	entries := make([]EventWrapper, 0, len(lines))
+	for _, line := range lines {
+		var wrapper EventWrapper
+		if err := json.Unmarshal(line, &wrapper); err != nil {
+			return nil, fmt.Errorf("failed to decode event wrapper: %w", err)
+		}
+		entries = append(entries, wrapper)
 	}
-	return evt, nil
+	return entries, nil
 }
 
-// Truncate rewrites the event log keeping only the first keepN events.
-func (s *Store) Truncate(keepN int) error {
+// readLog reads the header, if the log has one, and the lines of the entries after it.
+func (s *Store) readLog() (*LogHeader, [][]byte, error) {
 	if _, err := s.file.Seek(0, 0); err != nil {
-		return err
+		return nil, nil, err
 	}
 
+	var header *LogHeader
 	var lines [][]byte
 	scanner := bufio.NewScanner(s.file)
 	for scanner.Scan() {
-		lines = append(lines, append([]byte(nil), scanner.Bytes()...))
+		line := scanner.Bytes()
+		if len(lines) == 0 && header == nil {
+			var wrapper EventWrapper
+			if err := json.Unmarshal(line, &wrapper); err == nil && wrapper.Type == logHeaderType {
+				header = &LogHeader{}
+				if err := json.Unmarshal(wrapper.Data, header); err != nil {
+					return nil, nil, fmt.Errorf("failed to decode log header: %w", err)
+				}
+				continue
+			}
+		}
+		lines = append(lines, append([]byte(nil), line...))
 	}
-	if err := scanner.Err(); err != nil {
+	return header, lines, scanner.Err()
+}
+
+// Close flushes and closes the underlying file.
+func (s *Store) Close() error {
+	return s.file.Close()
+}
+
+// Truncate rewrites the event log keeping only the first keepN events.
+func (s *Store) Truncate(keepN int) error {
+	_, lines, err := s.readLog()
+	if err != nil {
 		return err
 	}
 
@@ -150,6 +229,47 @@ func (s *Store) Truncate(keepN int) error {
 	if keepN > len(lines) {
 		keepN = len(lines)
 	}
+	return s.rewrite(s.header, lines[:keepN])
+}
+
+// rewriteEntries replaces the log with the header and the given entries.
+func (s *Store) rewriteEntries(header LogHeader, entries []EventWrapper) error {
+	lines := make([][]byte, 0, len(entries))
+	for _, entry := range entries {
+		line, err := json.Marshal(entry)
+		if err != nil {
+			return fmt.Errorf("failed to marshal wrapper: %w", err)
+		}
+		lines = append(lines, line)
+	}
+	return s.rewrite(header, lines)
+}
+
+// setHeader replaces the log's header, keeping its entries.
+func (s *Store) setHeader(header LogHeader) error {
+	_, lines, err := s.readLog()
+	if err != nil {
+		return err
+	}
+	return s.rewrite(header, lines)
+}
+
+// rewrite replaces the log with the header and the given lines, through a temp file. When the
+// last entries are dropped, the header keeps the highest sequence number issued, so that
+// numbering goes on past them.
+func (s *Store) rewrite(header LogHeader, lines [][]byte) error {
+	if s.seq > lastSeq(lines) {
+		header.Seq = max(header.Seq, s.seq)
+	}
+	data, err := json.Marshal(header)
+	if err != nil {
+		return fmt.Errorf("failed to marshal log header: %w", err)
+	}
+	headerLine, err := json.Marshal(EventWrapper{Type: logHeaderType, Data: data})
+	if err != nil {
+		return fmt.Errorf("failed to marshal log header: %w", err)
+	}
+	lines = append([][]byte{headerLine}, lines...)
 
 	// Write to a temp file, then rename for atomicity
 	dir := filepath.Dir(s.file.Name())
@@ -159,7 +279,7 @@ func (s *Store) Truncate(keepN int) error {
 	}
 	tmpName := tmp.Name()
 
-	for _, line := range lines[:keepN] {
+	for _, line := range lines {
 		if _, err := tmp.Write(append(line, '\n')); err != nil {
 			tmp.Close()
 			os.Remove(tmpName)
@@ -186,18 +306,13 @@ func (s *Store) Truncate(keepN int) error {
 		return fmt.Errorf("failed to reopen event log: %w", err)
 	}
 	s.file = f
+	s.header = header
+	s.track(lines[1:])
 	return nil
 }
 
 // EventCount returns the number of events currently in the log.
 func (s *Store) EventCount() (int, error) {
-	if _, err := s.file.Seek(0, 0); err != nil {
-		return 0, err
-	}
-	count := 0
-	scanner := bufio.NewScanner(s.file)
-	for scanner.Scan() {
-		count++
-	}
-	return count, scanner.Err()
+	_, lines, err := s.readLog()
+	return len(lines), err
 }
@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -122,6 +123,11 @@ func TestStoreLoad_AllEventTypes(t *testing.T) {
 		&engine.TurnEndedEvent{LoopName: "combat", ActorID: "fighter"},
 		&engine.TurnStartedEvent{LoopName: "combat", ActorID: "wizard", Turn: 2},
 		&engine.RoundStartedEvent{LoopName: "combat", Round: 1},
+		&engine.HookAddedEvent{TargetID: "wizard", Hook: engine.Hook{Name: "end_dodge", Type: "next_actor_turn", SourceCommand: "dodge", Phase: engine.PhaseActor}},
+		&engine.HookFiredEvent{TargetID: "wizard", HookName: "end_dodge", Remaining: 1},
+		&engine.HookRemovedEvent{TargetID: "wizard", HookName: "end_dodge"},
+		&engine.SecretEvent{ID: 1, Events: []engine.Event{&engine.DiceRolledEvent{ActorID: "GM", Dice: "1d20", Result: 4}}},
+		&engine.SecretRevealedEvent{ID: 1, Messages: []string{"GM rolled 1d20 = 4"}},
 	}
 
 	for _, evt := range events {
@@ -141,6 +147,12 @@ func TestStoreLoad_AllEventTypes(t *testing.T) {
 	for i, evt := range loaded {
 		assert.Equal(t, events[i].Type(), evt.Type(), "event %d type mismatch", i)
 	}
+
+	// Secret events keep their wrapped events
+	secret, ok := loaded[len(loaded)-2].(*engine.SecretEvent)
+	require.True(t, ok)
+	require.Len(t, secret.Events, 1)
+	assert.Equal(t, &engine.DiceRolledEvent{ActorID: "GM", Dice: "1d20", Result: 4}, secret.Events[0])
 }
 
 func TestStoreLoad_UnknownEventType(t *testing.T) {
@@ -160,6 +172,30 @@ func TestStoreLoad_UnknownEventType(t *testing.T) {
 	assert.Contains(t, err.Error(), "unknown event type")
 }
 
+// futureEvent is an event type the store has no constructor for.
+type futureEvent struct{}
+
+func (e *futureEvent) Type() string                        { return "FutureEvent" }
+func (e *futureEvent) Apply(state *engine.GameState) error { return nil }
+func (e *futureEvent) Message() string                     { return "" }
+
+func TestStoreAppend_RefusesUnregisteredEventTypes(t *testing.T) {
+	store, err := NewStore(filepath.Join(t.TempDir(), "test.jsonl"))
+	require.NoError(t, err)
+	defer store.Close()
+
+	err = store.Append(&futureEvent{})
+	assert.EqualError(t, err, "refusing to log event: event type FutureEvent is not registered")
+	err = store.Append(&engine.SecretEvent{ID: 1, Events: []engine.Event{&engine.DeclaredEvent{EventType: "never_declared"}}})
+	assert.EqualError(t, err, "refusing to log event: secret #1: event type never_declared is not declared in the manifest")
+
+	engine.RegisterEvent("FutureEvent", func() engine.Event { return &futureEvent{} })
+	require.NoError(t, store.Append(&futureEvent{}))
+	events, err := store.Load()
+	require.NoError(t, err)
+	assert.IsType(t, &futureEvent{}, events[0])
+}
+
 func TestStoreLoad_InvalidJSON(t *testing.T) {
 	dir := t.TempDir()
 	path := filepath.Join(dir, "test.jsonl")
@@ -186,3 +222,45 @@ func TestNewStore_CreatesFile(t *testing.T) {
 	_, err = os.Stat(path)
 	assert.NoError(t, err)
 }
+
+func TestStoreAppendRecord_NumbersAndTimesEntries(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.jsonl")
+	require.NoError(t, os.WriteFile(path, []byte(`{"type":"LoopEvent","data":{"loop_name":"combat","active":true}}`+"\n"), 0644))
+
+	store, err := NewStore(path)
+	require.NoError(t, err)
+	before := time.Now().UTC()
+	env, err := store.AppendRecord(&engine.RoundStartedEvent{LoopName: "combat", Round: 1},
+		engine.Envelope{Actor: "GM", Frontend: FrontendTUI, Command: "next_round by: GM"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), env.Seq, "legacy entries are numbered by position")
+	assert.False(t, env.Time.Before(before))
+	require.NoError(t, store.Append(&engine.RoundStartedEvent{LoopName: "combat", Round: 2}))
+	require.NoError(t, store.Close())
+
+	store, err = NewStore(path)
+	require.NoError(t, err)
+	defer store.Close()
+	entries, err := store.entries()
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	assert.Equal(t, "GM", entries[1].Actor)
+	assert.Equal(t, FrontendTUI, entries[1].Frontend)
+	assert.Equal(t, "next_round by: GM", entries[1].Command)
+	assert.Equal(t, int64(3), entries[2].Seq)
+
+	// Undone entries keep their sequence numbers, also once the log is reopened
+	require.NoError(t, store.Truncate(2))
+	env, err = store.AppendRecord(&engine.RoundStartedEvent{LoopName: "combat", Round: 2}, engine.Envelope{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), env.Seq)
+	require.NoError(t, store.Truncate(2))
+	require.NoError(t, store.Close())
+
+	store, err = NewStore(path)
+	require.NoError(t, err)
+	defer store.Close()
+	env, err = store.AppendRecord(&engine.RoundStartedEvent{LoopName: "combat", Round: 2}, engine.Envelope{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), env.Seq)
+}
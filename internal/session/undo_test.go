@@ -195,7 +195,7 @@ func TestNextTurn_AdvancesTurn(t *testing.T) {
 	require.NoError(t, err)
 
 	// Manually add actors and set order
-	loop := s.State().Loops["encounter_start"]
+	loop := s.state.Loops["encounter_start"]
 	loop.Actors = []string{"fighter", "wizard", "rogue"}
 	loop.Order = map[string]int{"fighter": 20, "wizard": 12, "rogue": 15}
 
@@ -220,7 +220,7 @@ func TestNextTurn_RoundWraparound(t *testing.T) {
 	_, err := s.Execute("encounter start")
 	require.NoError(t, err)
 
-	loop := s.State().Loops["encounter_start"]
+	loop := s.state.Loops["encounter_start"]
 	loop.Actors = []string{"fighter", "wizard"}
 	loop.Order = map[string]int{"fighter": 20, "wizard": 12}
 
@@ -246,7 +246,7 @@ func TestUndoToBoundary_Turn(t *testing.T) {
 	_, err := s.Execute("encounter start")
 	require.NoError(t, err)
 
-	loop := s.State().Loops["encounter_start"]
+	loop := s.state.Loops["encounter_start"]
 	loop.Actors = []string{"fighter", "wizard"}
 	loop.Order = map[string]int{"fighter": 20, "wizard": 12}
 
@@ -269,7 +269,7 @@ func TestUndoToBoundary_Round(t *testing.T) {
 	_, err := s.Execute("encounter start")
 	require.NoError(t, err)
 
-	loop := s.State().Loops["encounter_start"]
+	loop := s.state.Loops["encounter_start"]
 	loop.Actors = []string{"fighter"}
 	loop.Order = map[string]int{"fighter": 20}
 
@@ -9,14 +9,19 @@ import (
 	"github.com/spf13/viper"
 )
 
-// CommandResult holds the output of a command execution.
+// CommandResult holds the output of a command execution. Messages are posted to the group chat,
+// which every player reads. Private messages are sent only to the user who issued the command,
+// in their private chat with the bot.
 type CommandResult struct {
 	Messages []string
+	Private  []string
 }
 
 // Executor defines the interface for running DSL commands.
 type Executor interface {
 	Execute(input string) (*CommandResult, error)
+	// Status describes the game state as the given actor is allowed to see it.
+	Status(actorID string) (*CommandResult, error)
 }
 
 // Bot handles the integration between Telegram and the Ancient Draconic session
@@ -90,19 +95,33 @@ func (b *Bot) handleMessage(msg *Message) {
 		return
 	}
 
-	// Inject by: <actor> after the first word (command)
-	translatedCmd := parts[0] + " by: " + actorID + " " + strings.Join(parts[1:], " ")
-
 	// 4. Execution
-	result, err := b.executor.Execute(translatedCmd)
+	var result *CommandResult
+	var err error
+	if parts[0] == "status" {
+		result, err = b.executor.Status(actorID)
+	} else {
+		// Inject by: <actor> after the first word (command)
+		translatedCmd := parts[0] + " by: " + actorID + " " + strings.Join(parts[1:], " ")
+		result, err = b.executor.Execute(translatedCmd)
+	}
 	if err != nil {
 		b.client.SendMessage(b.chatID, fmt.Sprintf("Error: %v", err))
 		return
 	}
 
-	for _, msg := range result.Messages {
-		if msg != "" {
-			b.client.SendMessage(b.chatID, fmt.Sprintf("*%s*", msg))
+	for _, text := range result.Messages {
+		if text != "" {
+			b.client.SendMessage(b.chatID, fmt.Sprintf("*%s*", text))
+		}
+	}
+	// A private chat has the user's ID; the user must have started a chat with the bot
+	for _, text := range result.Private {
+		if text == "" {
+			continue
+		}
+		if err := b.client.SendMessage(msg.From.ID, fmt.Sprintf("*%s*", text)); err != nil {
+			log.Printf("Error sending a private message to %d: %v", msg.From.ID, err)
 		}
 	}
 }
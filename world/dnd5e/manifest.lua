@@ -5,6 +5,18 @@ restrictions = {
     gm_commands = { "encounter_start", "encounter_end", "add_condition", "remove_condition" },
 }
 
+-- What players see of entities they do not control (the GM always sees everything)
+visibility = {
+    resources = {
+        hp = {
+            { at_most = 0, label = "down" },
+            { at_most = 0.5, label = "bloodied" },
+            { label = "healthy" },
+        },
+    },
+    hidden_conditions = { "invisible" },
+}
+
 local _sizes_list = { "tiny", "small", "medium", "large", "huge", "gargantuan" }
 local _skill_map = {
     athletics = "str",